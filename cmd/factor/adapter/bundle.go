@@ -0,0 +1,129 @@
+package adapter
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bookandmusic/dev-tools/internal/bundle"
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// bundleTarget 描述可以写入离线包的 manager
+type bundleTarget struct {
+	ManagerName string
+	Config      any
+	ContextMap  map[soft.ContextKey]any
+}
+
+func bundleTargets(cfg *config.GlobalConfig) []bundleTarget {
+	return []bundleTarget{
+		{ManagerName: "docker", Config: cfg.Docker},
+		{ManagerName: "containerd", Config: cfg.Containerd},
+		{ManagerName: "podman", Config: cfg.Podman},
+		{ManagerName: "k3s", Config: cfg.K3s},
+		{ManagerName: "ohmyzsh", Config: cfg.OhMyzsh, ContextMap: map[soft.ContextKey]any{"env": map[string]string{}}},
+		{ManagerName: "self", Config: cfg},
+	}
+}
+
+// NewBundlePlugin 生成供离线安装（--bundle）使用的离线包
+func NewBundlePlugin(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Build an offline bundle for air-gapped installs",
+	}
+	cmd.AddCommand(newBundleCreateCmd(ui, cfg))
+	return cmd
+}
+
+func newBundleCreateCmd(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Resolve versions and collect every artifact into one archive",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg.Common.Offline {
+				return fmt.Errorf("bundle create needs network access and cannot run with --offline or --bundle")
+			}
+			arch, _ := cmd.Flags().GetString("arch")
+			if arch == "" {
+				arch = string(utils.DetectArch())
+			}
+			if arch != string(utils.ArchX86_64) && arch != string(utils.ArchAARCH64) {
+				return fmt.Errorf("unsupported arch: %s (x86_64 or aarch64)", arch)
+			}
+			output, _ := cmd.Flags().GetString("output")
+			if output == "" {
+				output = fmt.Sprintf("dev-tools-bundle-%s.tar.gz", arch)
+			}
+			only, _ := cmd.Flags().GetStringSlice("only")
+
+			var targets []bundleTarget
+			for _, target := range bundleTargets(cfg) {
+				if len(only) == 0 || slices.Contains(only, target.ManagerName) {
+					targets = append(targets, target)
+				}
+			}
+			if len(targets) == 0 {
+				return fmt.Errorf("nothing to bundle, --only accepts: docker, containerd, podman, k3s, ohmyzsh, self")
+			}
+
+			if cfg.Common.DryRun {
+				for _, target := range targets {
+					ui.Info("Would bundle %s for %s into %s", target.ManagerName, arch, output)
+				}
+				return nil
+			}
+
+			w, err := bundle.Create(output, arch)
+			if err != nil {
+				return err
+			}
+			for _, target := range targets {
+				m, err := soft.GetManager(target.ManagerName)
+				if err != nil {
+					w.Abort()
+					return err
+				}
+				bundler, ok := m.(soft.Bundler)
+				if !ok {
+					w.Abort()
+					return fmt.Errorf("%s cannot be bundled", target.ManagerName)
+				}
+				ui.Info("Bundling %s...", target.ManagerName)
+				ctx := buildContext(ui, cfg, target.Config, target.ContextMap, cmd)
+				if err := bundler.Bundle(ctx, w); err != nil {
+					w.Abort()
+					return fmt.Errorf("failed to bundle %s: %w", target.ManagerName, err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				_ = os.Remove(output)
+				return err
+			}
+
+			manifest := w.Manifest()
+			var versions []string
+			for name, version := range manifest.Versions {
+				versions = append(versions, name+"="+version)
+			}
+			slices.Sort(versions)
+			if len(versions) > 0 {
+				ui.Info("Versions: %s", strings.Join(versions, ", "))
+			}
+			ui.Success("Bundle with %d artifact(s) written to %s, install with `dtl --bundle %s <command> install`", len(manifest.Files), output, output)
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", "Output file (default dev-tools-bundle-<arch>.tar.gz)")
+	cmd.Flags().String("arch", "", "Target architecture: x86_64 or aarch64 (default: this host)")
+	cmd.Flags().StringSlice("only", nil, "Only bundle these managers (docker, containerd, podman, k3s, ohmyzsh, self)")
+	return cmd
+}
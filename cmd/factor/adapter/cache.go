@@ -0,0 +1,254 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bookandmusic/dev-tools/internal/cache"
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// NewCachePlugin 管理 CacheDir 中下载的安装包
+func NewCachePlugin(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage downloaded artifacts in the cache dir (list, prune, verify, export, import)",
+	}
+	cmd.AddCommand(
+		newCacheListCmd(ui, cfg),
+		newCachePruneCmd(ui, cfg),
+		newCacheVerifyCmd(ui, cfg),
+		newCacheExportCmd(ui, cfg),
+		newCacheImportCmd(ui, cfg),
+	)
+	return cmd
+}
+
+// cacheContext 返回带干跑设置的 context
+func cacheContext(cfg *config.GlobalConfig) context.Context {
+	ctx := context.Background()
+	if cfg.Common.DryRun {
+		ctx = utils.WithDryRun(ctx, &utils.DryRun{})
+	}
+	return ctx
+}
+
+func newCacheListCmd(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List cached artifacts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := cache.Open(cfg.Common.CacheDir)
+			if err != nil {
+				return err
+			}
+			if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+				data, err := json.MarshalIndent(c.Entries, "", "  ")
+				if err != nil {
+					return err
+				}
+				ui.Println("%s", data)
+				return nil
+			}
+
+			entries := append([]*cache.Entry(nil), c.Entries...)
+			sort.Slice(entries, func(i, j int) bool {
+				if entries[i].Name != entries[j].Name {
+					return entries[i].Name < entries[j].Name
+				}
+				return entries[i].LastUsed.After(entries[j].LastUsed)
+			})
+			ui.Println("%-20s %-12s %-8s %-10s %-17s %s", "NAME", "VERSION", "ARCH", "SIZE", "LAST USED", "PATH")
+			for _, e := range entries {
+				ui.Println("%-20s %-12s %-8s %-10s %-17s %s", e.Name, e.Version, e.Arch, humanSize(e.Size), e.LastUsed.Format("2006-01-02 15:04"), e.Path)
+			}
+			ui.Info("%d artifact(s), %s in %s", len(entries), humanSize(c.TotalSize()), c.Dir)
+
+			untracked, err := c.Untracked()
+			if err != nil {
+				return err
+			}
+			if len(untracked) > 0 {
+				ui.Warning("%d file(s) in the cache are not in the index: %s", len(untracked), strings.Join(untracked, ", "))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool("json", false, "Print entries as JSON")
+	return cmd
+}
+
+func newCachePruneCmd(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove artifacts not used for a while or beyond the last N versions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			olderThanStr, _ := cmd.Flags().GetString("older-than")
+			keep, _ := cmd.Flags().GetInt("keep")
+			if olderThanStr == "" && keep <= 0 {
+				return fmt.Errorf("specify --older-than and/or --keep")
+			}
+			var olderThan time.Duration
+			if olderThanStr != "" {
+				var err error
+				if olderThan, err = parseAge(olderThanStr); err != nil {
+					return err
+				}
+			}
+
+			c, err := cache.Open(cfg.Common.CacheDir)
+			if err != nil {
+				return err
+			}
+			ctx := cacheContext(cfg)
+			defer printDryRun(ctx, ui)
+			removed, err := c.Prune(ctx, olderThan, keep)
+			if err != nil {
+				return err
+			}
+			var freed int64
+			for _, e := range removed {
+				ui.Info("Removed %s", e.Path)
+				freed += e.Size
+			}
+			if err := c.Save(ctx); err != nil {
+				return err
+			}
+			ui.Success("Pruned %d artifact(s), freed %s", len(removed), humanSize(freed))
+			return nil
+		},
+	}
+	cmd.Flags().String("older-than", "", "Remove artifacts not used within this duration (e.g. 30d, 72h)")
+	cmd.Flags().Int("keep", 0, "Keep only the N most recently used versions of each artifact")
+	return cmd
+}
+
+func newCacheVerifyCmd(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Re-verify the SHA256 of cached artifacts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := cache.Open(cfg.Common.CacheDir)
+			if err != nil {
+				return err
+			}
+			problems := c.Verify()
+			for _, e := range c.Entries {
+				if err, bad := problems[e]; bad {
+					ui.Error("%s: %v", e.Path, err)
+				} else if e.SHA256 == "" {
+					ui.Warning("%s: no checksum recorded", e.Path)
+				} else {
+					ui.Info("%s: ok", e.Path)
+				}
+			}
+			if len(problems) == 0 {
+				ui.Success("All %d artifact(s) verified", len(c.Entries))
+				return nil
+			}
+
+			if evict, _ := cmd.Flags().GetBool("evict"); evict {
+				ctx := cacheContext(cfg)
+				defer printDryRun(ctx, ui)
+				for e := range problems {
+					if err := c.Remove(ctx, e); err != nil {
+						return err
+					}
+				}
+				if err := c.Save(ctx); err != nil {
+					return err
+				}
+				ui.Success("Evicted %d corrupt artifact(s)", len(problems))
+				return nil
+			}
+			return fmt.Errorf("%d corrupt artifact(s), run with --evict to remove them", len(problems))
+		},
+	}
+	cmd.Flags().Bool("evict", false, "Remove corrupt or missing artifacts from the cache")
+	return cmd
+}
+
+func newCacheExportCmd(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "export <file.tar.gz>",
+		Short: "Export the cache as a tarball for air-gapped machines",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := cache.Open(cfg.Common.CacheDir)
+			if err != nil {
+				return err
+			}
+			ctx := cacheContext(cfg)
+			defer printDryRun(ctx, ui)
+			if err := c.Export(ctx, args[0]); err != nil {
+				return err
+			}
+			ui.Success("Exported %d artifact(s) (%s) to %s", len(c.Entries), humanSize(c.TotalSize()), args[0])
+			return nil
+		},
+	}
+}
+
+func newCacheImportCmd(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <file.tar.gz>",
+		Short: "Import a tarball created by cache export",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := cache.Open(cfg.Common.CacheDir)
+			if err != nil {
+				return err
+			}
+			ctx := cacheContext(cfg)
+			defer printDryRun(ctx, ui)
+			count, err := c.Import(ctx, ui, args[0])
+			if err != nil {
+				return err
+			}
+			ui.Success("Imported %d artifact(s) into %s", count, c.Dir)
+			return nil
+		},
+	}
+}
+
+// parseAge 解析时长，在 time.ParseDuration 的基础上支持天（如 "30d"）
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration: %s", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return d, nil
+}
+
+// humanSize 以 KB/MB/GB 显示文件大小
+func humanSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
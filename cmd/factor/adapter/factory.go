@@ -2,19 +2,23 @@ package adapter
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/spf13/cobra"
 
 	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/manager/language"
 	"github.com/bookandmusic/dev-tools/internal/manager/soft"
 	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
 )
 
 type SubcommandSpec struct {
-	Name   string
-	Short  string
-	Action func(ctx context.Context, manager soft.SoftManage) error
-	Flags  func(cmd *cobra.Command) // 可选：不同命令需要的 flags
+	Name        string
+	Short       string
+	Action      func(ctx context.Context, manager soft.SoftManage) error
+	Flags       func(cmd *cobra.Command) // 可选：不同命令需要的 flags
+	Subcommands []SubcommandSpec         // 可选：嵌套的子命令，如 docker config show
 }
 
 type PluginSpec struct {
@@ -44,32 +48,173 @@ func BuildPlugin(ui ui.UI, cfg *config.GlobalConfig, spec PluginSpec) *cobra.Com
 	}
 
 	for _, sub := range spec.Subcommands {
-		subCmd := &cobra.Command{
-			Use:   sub.Name,
-			Short: sub.Short,
-			RunE: func(cmd *cobra.Command, args []string) error {
-				ctx := context.Background()
-				// 默认公共参数
-				ctx = context.WithValue(ctx, soft.ContextKey("cfg"), spec.Config)
-				ctx = context.WithValue(ctx, soft.ContextKey("global"), cfg.Common)
-				ctx = context.WithValue(ctx, soft.ContextKey("ui"), ui)
-
-				// 插件自定义 context
-				for k, v := range spec.ContextMap {
-					ctx = context.WithValue(ctx, k, v)
-				}
+		rootCmd.AddCommand(buildSubcommand(ui, cfg, spec, sub, &manager))
+	}
 
-				// 有时需要把 cmd 本身传进去，方便读取 flags
-				ctx = context.WithValue(ctx, soft.ContextKey("cmd"), cmd)
+	return rootCmd
+}
 
-				return sub.Action(ctx, manager)
-			},
+// buildSubcommand 根据 SubcommandSpec 生成命令，manager 在 PersistentPreRunE 中赋值
+func buildSubcommand(ui ui.UI, cfg *config.GlobalConfig, spec PluginSpec, sub SubcommandSpec, manager *soft.SoftManage) *cobra.Command {
+	subCmd := &cobra.Command{
+		Use:   sub.Name,
+		Short: sub.Short,
+	}
+	if sub.Action != nil {
+		subCmd.RunE = func(cmd *cobra.Command, args []string) error {
+			ctx := buildContext(ui, cfg, spec.Config, spec.ContextMap, cmd)
+			defer printDryRun(ctx, ui)
+			return sub.Action(ctx, *manager)
 		}
-		if sub.Flags != nil {
-			sub.Flags(subCmd)
+	}
+	if sub.Flags != nil {
+		sub.Flags(subCmd)
+	}
+	for _, child := range sub.Subcommands {
+		subCmd.AddCommand(buildSubcommand(ui, cfg, spec, child, manager))
+	}
+	return subCmd
+}
+
+// buildContext 构建传递给 manager 的 context
+func buildContext(ui ui.UI, cfg *config.GlobalConfig, specCfg any, contextMap map[soft.ContextKey]any, cmd *cobra.Command) context.Context {
+	ctx := context.Background()
+	// 默认公共参数
+	ctx = context.WithValue(ctx, soft.ContextKey("cfg"), specCfg)
+	ctx = context.WithValue(ctx, soft.ContextKey("global"), cfg.Common)
+	ctx = context.WithValue(ctx, soft.ContextKey("ui"), ui)
+
+	// 插件自定义 context
+	for k, v := range contextMap {
+		ctx = context.WithValue(ctx, k, v)
+	}
+
+	// 有时需要把 cmd 本身传进去，方便读取 flags
+	ctx = context.WithValue(ctx, soft.ContextKey("cmd"), cmd)
+
+	// --dry-run：所有写操作只记录为计划动作
+	if cfg.Common.DryRun {
+		ctx = utils.WithDryRun(ctx, &utils.DryRun{})
+	}
+	// --offline / --bundle：禁止任何网络访问
+	if cfg.Common.Offline {
+		ctx = utils.WithOffline(ctx)
+	}
+	return ctx
+}
+
+// printDryRun 干跑模式下输出记录的计划动作
+func printDryRun(ctx context.Context, ui ui.UI) {
+	if plan := utils.DryRunFromContext(ctx); plan != nil {
+		plan.Print(ui)
+	}
+}
+
+type LanguageSpec struct {
+	Name        string
+	Description string
+	ManagerName string
+	Config      *config.LangConfig
+}
+
+// BuildLanguagePlugin 为已注册的语言生成 install|uninstall|list|use|current 子命令
+// install 与 use 的版本参数必须出现在 LangConfig.Versions 中（versions 为空时不限制）
+func BuildLanguagePlugin(ui ui.UI, cfg *config.GlobalConfig, spec LanguageSpec) *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   spec.Name,
+		Short: spec.Description,
+	}
+
+	var manager language.Manager
+
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		m, err := language.GetManager(spec.ManagerName)
+		if err != nil {
+			return err
 		}
-		rootCmd.AddCommand(subCmd)
+		manager = m
+		return nil
 	}
 
+	rootCmd.AddCommand(
+		&cobra.Command{
+			Use:   "install [version...]",
+			Short: fmt.Sprintf("Install %s versions (defaults to versions in config)", spec.Name),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				versions := spec.Config.Versions
+				if len(args) > 0 {
+					versions = make([]string, 0, len(args))
+					for _, arg := range args {
+						v, err := language.MatchVersion(manager, spec.Config.Versions, arg)
+						if err != nil {
+							return err
+						}
+						versions = append(versions, v)
+					}
+				}
+				if len(versions) == 0 {
+					return fmt.Errorf("no version given and no versions configured for %s", spec.Name)
+				}
+				ctx := buildContext(ui, cfg, spec.Config, nil, cmd)
+				defer printDryRun(ctx, ui)
+				for _, v := range versions {
+					if err := manager.Install(ctx, v); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "uninstall <version>",
+			Short: fmt.Sprintf("Uninstall a %s version", spec.Name),
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				ctx := buildContext(ui, cfg, spec.Config, nil, cmd)
+				defer printDryRun(ctx, ui)
+				return manager.Uninstall(ctx, args[0])
+			},
+		},
+		&cobra.Command{
+			Use:   "list",
+			Short: fmt.Sprintf("List installed and configured %s versions", spec.Name),
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return manager.List(buildContext(ui, cfg, spec.Config, nil, cmd))
+			},
+		},
+		&cobra.Command{
+			Use:   "use <version>",
+			Short: fmt.Sprintf("Activate a %s version in the tools .env", spec.Name),
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				v, err := language.MatchVersion(manager, spec.Config.Versions, args[0])
+				if err != nil {
+					return err
+				}
+				ctx := buildContext(ui, cfg, spec.Config, nil, cmd)
+				defer printDryRun(ctx, ui)
+				return manager.Active(ctx, v)
+			},
+		},
+		&cobra.Command{
+			Use:   "current",
+			Short: fmt.Sprintf("Show the active %s version", spec.Name),
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				v, err := manager.Current(buildContext(ui, cfg, spec.Config, nil, cmd))
+				if err != nil {
+					return err
+				}
+				if v == "" {
+					ui.Warning("No %s version is active", spec.Name)
+					return nil
+				}
+				ui.Println("%s", v)
+				return nil
+			},
+		},
+	)
+
 	return rootCmd
 }
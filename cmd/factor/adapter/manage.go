@@ -2,11 +2,15 @@ package adapter
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/spf13/cobra"
 
+	"github.com/bookandmusic/dev-tools/cmd/factor/loader"
 	"github.com/bookandmusic/dev-tools/cmd/plugin"
 	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/manager/language"
 	"github.com/bookandmusic/dev-tools/internal/manager/soft"
 	"github.com/bookandmusic/dev-tools/internal/ui"
 )
@@ -17,7 +21,7 @@ func NewSelfPlugin(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
 		Description: "manage dev-tools for install, uninstall",
 		ManagerName: "self",
 		Config:      cfg,
-		ContextMap:  nil,
+		ContextMap:  map[soft.ContextKey]any{"count-plugins": loader.CountPlugins}, // status 按加载规则统计插件
 		Subcommands: []SubcommandSpec{
 			{
 				Name:  "install",
@@ -36,6 +40,7 @@ func NewSelfPlugin(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
 			},
 			{Name: "uninstall", Short: "Uninstall self", Action: func(ctx context.Context, m soft.SoftManage) error { return m.Uninstall(ctx) }, Flags: nil},
 			{Name: "update", Short: "Update self", Action: func(ctx context.Context, m soft.SoftManage) error { return m.Update(ctx) }, Flags: nil},
+			newStatusSubcommand("Self", ui),
 		},
 	})
 }
@@ -47,7 +52,7 @@ func NewOhMyzshPlugin(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
 		ManagerName: "ohmyzsh",
 		Config:      cfg.OhMyzsh,
 		ContextMap:  map[soft.ContextKey]any{"env": map[string]string{}}, // 自定义 env
-		Subcommands: createStandardSubcommands("OhMyzsh"),
+		Subcommands: createStandardSubcommands("OhMyzsh", ui),
 	})
 }
 
@@ -56,23 +61,183 @@ func NewDockerPlugin(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
 		Name:        "docker",
 		Description: "managedocker for install, uninstall",
 		ManagerName: "docker",
-		Config:      cfg,
+		Config:      cfg.Docker,
 		ContextMap:  nil,
-		Subcommands: createStandardSubcommands("Docker"),
+		Subcommands: append(createStandardSubcommands("Docker", ui), newRollbackSubcommand("Docker"), newConfigSubcommand("Docker", "daemon.json")),
+	})
+}
+
+func NewContainerdPlugin(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	return BuildPlugin(ui, cfg, PluginSpec{
+		Name:        "containerd",
+		Description: "manage standalone containerd with runc, nerdctl and CNI plugins",
+		ManagerName: "containerd",
+		Config:      cfg.Containerd,
+		Subcommands: createStandardSubcommands("Containerd", ui),
 	})
 }
 
-// createStandardSubcommands 创建标准的子命令（install, uninstall, update）
-func createStandardSubcommands(prefix string) []SubcommandSpec {
+func NewPodmanPlugin(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	return BuildPlugin(ui, cfg, PluginSpec{
+		Name:        "podman",
+		Description: "manage static podman for install, uninstall, update",
+		ManagerName: "podman",
+		Config:      cfg.Podman,
+		Subcommands: createStandardSubcommands("Podman", ui),
+	})
+}
+
+func NewK3sPlugin(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	return BuildPlugin(ui, cfg, PluginSpec{
+		Name:        "k3s",
+		Description: "manage k3s for install, uninstall, update",
+		ManagerName: "k3s",
+		Config:      cfg.K3s,
+		Subcommands: createStandardSubcommands("K3s", ui),
+	})
+}
+
+// createStandardSubcommands 创建标准的子命令（install, uninstall, update, status）
+func createStandardSubcommands(prefix string, ui ui.UI) []SubcommandSpec {
 	return []SubcommandSpec{
 		{Name: "install", Short: prefix + " Install", Action: func(ctx context.Context, m soft.SoftManage) error { return m.Install(ctx) }, Flags: nil},
 		{Name: "uninstall", Short: prefix + " Uninstall", Action: func(ctx context.Context, m soft.SoftManage) error { return m.Uninstall(ctx) }, Flags: nil},
 		{Name: "update", Short: prefix + " Update", Action: func(ctx context.Context, m soft.SoftManage) error { return m.Update(ctx) }, Flags: nil},
+		newStatusSubcommand(prefix, ui),
+	}
+}
+
+// newRollbackSubcommand 创建 rollback 子命令，manager 需实现 soft.Rollbacker
+func newRollbackSubcommand(prefix string) SubcommandSpec {
+	return SubcommandSpec{
+		Name:  "rollback",
+		Short: prefix + " Rollback the last install or update",
+		Action: func(ctx context.Context, m soft.SoftManage) error {
+			r, ok := m.(soft.Rollbacker)
+			if !ok {
+				return fmt.Errorf("%s does not support rollback", prefix)
+			}
+			return r.Rollback(ctx)
+		},
+	}
+}
+
+// newConfigSubcommand 创建 config show|diff|apply 子命令，manager 需实现 soft.Configurer
+func newConfigSubcommand(prefix, file string) SubcommandSpec {
+	// configAction 检查 manager 是否支持配置管理
+	configAction := func(fn func(c soft.Configurer, ctx context.Context) error) func(ctx context.Context, m soft.SoftManage) error {
+		return func(ctx context.Context, m soft.SoftManage) error {
+			c, ok := m.(soft.Configurer)
+			if !ok {
+				return fmt.Errorf("%s does not support config management", prefix)
+			}
+			return fn(c, ctx)
+		}
+	}
+	return SubcommandSpec{
+		Name:  "config",
+		Short: fmt.Sprintf("%s Manage %s from config.yml", prefix, file),
+		Subcommands: []SubcommandSpec{
+			{
+				Name:   "show",
+				Short:  fmt.Sprintf("Print the merged %s", file),
+				Action: configAction(soft.Configurer.ShowConfig),
+			},
+			{
+				Name:   "diff",
+				Short:  fmt.Sprintf("Show the changes apply would make to %s", file),
+				Action: configAction(soft.Configurer.DiffConfig),
+			},
+			{
+				Name:   "apply",
+				Short:  fmt.Sprintf("Write the merged %s and reload the service", file),
+				Action: configAction(soft.Configurer.ApplyConfig),
+			},
+		},
+	}
+}
+
+// newStatusSubcommand 创建 status 子命令，--json 输出便于脚本处理
+func newStatusSubcommand(prefix string, ui ui.UI) SubcommandSpec {
+	return SubcommandSpec{
+		Name:  "status",
+		Short: prefix + " Status",
+		Action: func(ctx context.Context, m soft.SoftManage) error {
+			status, err := m.Status(ctx)
+			if err != nil {
+				return err
+			}
+			cmd := ctx.Value(soft.ContextKey("cmd")).(*cobra.Command)
+			if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+				data, err := json.MarshalIndent(status, "", "  ")
+				if err != nil {
+					return err
+				}
+				ui.Println("%s", data)
+				return nil
+			}
+			printStatus(ui, status)
+			return nil
+		},
+		Flags: func(cmd *cobra.Command) {
+			cmd.Flags().Bool("json", false, "Print status as JSON")
+		},
+	}
+}
+
+// printStatus 以表格形式输出状态
+func printStatus(ui ui.UI, status *soft.Status) {
+	if !status.Installed {
+		ui.Warning("%s is not installed (%s)", status.Name, status.Path)
+		return
+	}
+	if status.Version != "" {
+		ui.Success("%s %s installed in %s", status.Name, status.Version, status.Path)
+	} else {
+		ui.Success("%s installed in %s", status.Name, status.Path)
+	}
+	if len(status.Components) == 0 {
+		return
+	}
+	ui.Println("%-32s %-12s %-20s %s", "COMPONENT", "VERSION", "STATE", "PATH")
+	for _, c := range status.Components {
+		ui.Println("%-32s %-12s %-20s %s", c.Name, c.Version, c.State, c.Path)
 	}
 }
 
 func LoadPluginsFromAdapter(ui ui.UI, cfg *config.GlobalConfig) {
 	plugin.Register(NewDockerPlugin(ui, cfg))
+	plugin.Register(NewContainerdPlugin(ui, cfg))
+	plugin.Register(NewPodmanPlugin(ui, cfg))
+	plugin.Register(NewK3sPlugin(ui, cfg))
 	plugin.Register(NewOhMyzshPlugin(ui, cfg))
 	plugin.Register(NewSelfPlugin(ui, cfg))
-}
\ No newline at end of file
+	plugin.Register(NewSyncPlugin(ui, cfg))
+	plugin.Register(NewToolPlugin(ui, cfg))
+	plugin.Register(NewCachePlugin(ui, cfg))
+	plugin.Register(NewBundlePlugin(ui, cfg))
+	plugin.Register(NewPluginManagePlugin(ui, cfg))
+	// 每个已注册的语言都会生成同名命令
+	for _, name := range language.Names() {
+		if langCfg := languageConfig(cfg, name); langCfg != nil {
+			plugin.Register(BuildLanguagePlugin(ui, cfg, LanguageSpec{
+				Name:        name,
+				Description: fmt.Sprintf("manage %s versions for install, uninstall, list, use", name),
+				ManagerName: name,
+				Config:      langCfg,
+			}))
+		}
+	}
+}
+
+// languageConfig 返回语言对应的配置段
+func languageConfig(cfg *config.GlobalConfig, name string) *config.LangConfig {
+	switch name {
+	case "python":
+		return cfg.Python
+	case "go":
+		return cfg.Go
+	default:
+		return nil
+	}
+}
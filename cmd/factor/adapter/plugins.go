@@ -0,0 +1,170 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bookandmusic/dev-tools/cmd/factor/loader"
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+)
+
+// NewPluginManagePlugin 管理 RootDir/plugins 下的脚本插件包（install、update、remove、list）
+func NewPluginManagePlugin(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Manage script plugin packages (install, update, remove, list)",
+	}
+	cmd.AddCommand(
+		newPluginInstallCmd(ui, cfg),
+		newPluginUpdateCmd(ui, cfg),
+		newPluginRemoveCmd(ui, cfg),
+		newPluginListCmd(ui, cfg),
+	)
+	return cmd
+}
+
+// runPluginAction 对每个参数依次执行 action，单个插件失败不影响其他插件
+func runPluginAction(ui ui.UI, cfg *config.GlobalConfig, name string, targets []string, action func(*loader.Packages, context.Context, string) error) error {
+	ctx := toolContext(cfg)
+	defer printDryRun(ctx, ui)
+
+	packages := &loader.Packages{UI: ui, Global: cfg.Common}
+	var failed []string
+	for _, target := range targets {
+		if err := action(packages, ctx, target); err != nil {
+			ui.Error("%s %s failed: %v", name, target, err)
+			failed = append(failed, target)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%s failed for: %s", name, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func newPluginInstallCmd(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "install <source...>",
+		Short: "Install plugins from a git URL (url#ref), a local directory or a .tar.gz file/URL",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPluginAction(ui, cfg, "install", args, func(p *loader.Packages, ctx context.Context, source string) error {
+				return p.Install(ctx, source, force)
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "Replace an existing plugin with the same name")
+	return cmd
+}
+
+func newPluginUpdateCmd(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "update [plugin...]",
+		Short: "Reinstall plugins from their recorded source (defaults to all installed from a source)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				manifest, err := loader.OpenManifest(loader.PluginDir(cfg.Common))
+				if err != nil {
+					return err
+				}
+				for name := range manifest.Plugins {
+					args = append(args, name)
+				}
+				sort.Strings(args)
+				if len(args) == 0 {
+					ui.Info("No plugins were installed from a source")
+					return nil
+				}
+			}
+			return runPluginAction(ui, cfg, "update", args, (*loader.Packages).Update)
+		},
+	}
+}
+
+func newPluginRemoveCmd(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <plugin...>",
+		Short: "Remove installed plugins",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPluginAction(ui, cfg, "remove", args, (*loader.Packages).Remove)
+		},
+	}
+}
+
+// pluginInfo list --json 的输出
+type pluginInfo struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Version  string   `json:"version,omitempty"`
+	Source   string   `json:"source"`
+	Commit   string   `json:"commit,omitempty"`
+	Path     string   `json:"path"`
+	Commands []string `json:"commands"`
+}
+
+func newPluginListCmd(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List plugins with their type, version, source and commands",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			packages := &loader.Packages{UI: ui, Global: cfg.Common}
+			plugins, err := packages.List()
+			if err != nil {
+				return err
+			}
+
+			infos := make([]pluginInfo, 0, len(plugins))
+			for _, p := range plugins {
+				info := pluginInfo{
+					Name:     p.Meta.Name,
+					Type:     p.Meta.Type,
+					Version:  p.Meta.Version,
+					Source:   "local",
+					Path:     p.Path,
+					Commands: p.Meta.CommandTree(),
+				}
+				if p.Entry != nil {
+					info.Source, info.Commit = p.Entry.Source, p.Entry.Commit
+				}
+				infos = append(infos, info)
+			}
+			if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+				data, err := json.MarshalIndent(infos, "", "  ")
+				if err != nil {
+					return err
+				}
+				ui.Println("%s", data)
+				return nil
+			}
+
+			ui.Println("%-20s %-8s %-10s %s", "NAME", "TYPE", "VERSION", "SOURCE")
+			for _, info := range infos {
+				version := info.Version
+				if version == "" {
+					version = "-"
+				}
+				source := info.Source
+				if len(info.Commit) >= 7 {
+					source += "@" + info.Commit[:7]
+				}
+				ui.Println("%-20s %-8s %-10s %s", info.Name, info.Type, version, source)
+				for _, line := range info.Commands {
+					ui.Println("%s", line)
+				}
+			}
+			ui.Info("%d plugin(s) in %s", len(infos), packages.Dir())
+			return nil
+		},
+	}
+	cmd.Flags().Bool("json", false, "Print plugins as JSON")
+	return cmd
+}
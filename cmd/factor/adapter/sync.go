@@ -0,0 +1,224 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/manager/language"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+)
+
+// syncResult 记录单个配置段的同步结果，用于最后输出汇总表
+type syncResult struct {
+	Name   string
+	Action soft.Action
+	Result string
+	Detail string
+}
+
+// syncTarget 描述 config.yml 中的一个配置段由哪个 manager 负责
+type syncTarget struct {
+	Section     string // config.yml 中的键名
+	ManagerName string
+	Language    bool // true 表示由 language.Manager 管理
+	Config      any
+	ContextMap  map[soft.ContextKey]any
+}
+
+func NewSyncPlugin(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Reconcile the machine with config.yml (install, update or skip each section)",
+		Long: `Reconcile the machine with config.yml (install, update or skip each section).
+
+Sections reconciled: docker, containerd, podman, k3s, oh-my-zsh, python and go.
+The ansible section only configures the plugin runtime and is not reconciled.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			check, _ := cmd.Flags().GetBool("check")
+			return runSync(cmd, ui, cfg, check)
+		},
+	}
+	cmd.Flags().Bool("check", false, "Only show what would be done, do not change anything")
+	return cmd
+}
+
+func syncTargets(cfg *config.GlobalConfig) []syncTarget {
+	return []syncTarget{
+		{Section: "docker", ManagerName: "docker", Config: cfg.Docker},
+		{Section: "containerd", ManagerName: "containerd", Config: cfg.Containerd},
+		{Section: "podman", ManagerName: "podman", Config: cfg.Podman},
+		{Section: "k3s", ManagerName: "k3s", Config: cfg.K3s},
+		{Section: "oh-my-zsh", ManagerName: "ohmyzsh", Config: cfg.OhMyzsh, ContextMap: map[soft.ContextKey]any{"env": map[string]string{}}},
+		{Section: "python", ManagerName: "python", Language: true, Config: cfg.Python},
+		{Section: "go", ManagerName: "go", Language: true, Config: cfg.Go},
+	}
+}
+
+func runSync(cmd *cobra.Command, ui ui.UI, cfg *config.GlobalConfig, check bool) error {
+	var results []syncResult
+	failed := 0
+
+	for _, target := range syncTargets(cfg) {
+		if !cfg.Declared(target.Section) {
+			results = append(results, syncResult{Name: target.Section, Action: soft.ActionSkip, Result: "-", Detail: "not declared in config"})
+			continue
+		}
+		ctx := buildContext(ui, cfg, target.Config, target.ContextMap, cmd)
+
+		var res syncResult
+		if target.Language {
+			res = syncLanguage(ctx, ui, target, check)
+		} else {
+			res = syncSoft(ctx, ui, target, check)
+		}
+		printDryRun(ctx, ui)
+		if res.Result == "failed" {
+			failed++
+		}
+		results = append(results, res)
+	}
+
+	printSyncSummary(ui, results)
+	if failed > 0 {
+		return fmt.Errorf("sync failed for %d section(s)", failed)
+	}
+	return nil
+}
+
+// syncSoft 通过 soft.Reconciler 判断动作并执行 Install/Update
+func syncSoft(ctx context.Context, ui ui.UI, target syncTarget, check bool) syncResult {
+	res := syncResult{Name: target.Section}
+
+	m, err := soft.GetManager(target.ManagerName)
+	if err != nil {
+		res.Action, res.Result, res.Detail = soft.ActionSkip, "-", "no manager available"
+		return res
+	}
+	reconciler, ok := m.(soft.Reconciler)
+	if !ok {
+		res.Action, res.Result, res.Detail = soft.ActionSkip, "-", "manager cannot report its state"
+		return res
+	}
+
+	action, reason, err := reconciler.Plan(ctx)
+	if err != nil {
+		res.Action, res.Result, res.Detail = soft.ActionSkip, "failed", err.Error()
+		return res
+	}
+	res.Action, res.Detail = action, reason
+
+	if action == soft.ActionSkip || check {
+		res.Result = resultFor(action, check)
+		return res
+	}
+
+	ui.Info("[sync] %s: %s (%s)", target.Section, action, reason)
+	if action == soft.ActionUpdate {
+		err = m.Update(ctx)
+	} else {
+		err = m.Install(ctx)
+	}
+	if err != nil {
+		res.Result, res.Detail = "failed", err.Error()
+		return res
+	}
+	res.Result = "ok"
+	return res
+}
+
+// syncLanguage 安装缺失的版本，并激活配置的 global 版本
+func syncLanguage(ctx context.Context, ui ui.UI, target syncTarget, check bool) syncResult {
+	res := syncResult{Name: target.Section}
+	langCfg := target.Config.(*config.LangConfig)
+
+	m, err := language.GetManager(target.ManagerName)
+	if err != nil {
+		res.Action, res.Result, res.Detail = soft.ActionSkip, "-", "no manager available"
+		return res
+	}
+
+	installed, err := language.InstalledVersions(langCfg.BaseDir)
+	if err != nil {
+		res.Action, res.Result, res.Detail = soft.ActionSkip, "failed", err.Error()
+		return res
+	}
+	current, err := m.Current(ctx)
+	if err != nil {
+		res.Action, res.Result, res.Detail = soft.ActionSkip, "failed", err.Error()
+		return res
+	}
+
+	var missing []string
+	for _, v := range langCfg.Versions {
+		if !language.Satisfied(m, installed, v) {
+			missing = append(missing, v)
+		}
+	}
+	global := m.Normalize(langCfg.Global)
+	switchGlobal := global != "" && !language.Matches(global, current)
+
+	var reasons []string
+	if len(missing) > 0 {
+		reasons = append(reasons, "missing "+strings.Join(missing, ", "))
+	}
+	if switchGlobal {
+		reasons = append(reasons, "activate "+global)
+	}
+
+	switch {
+	case len(missing) > 0:
+		res.Action = soft.ActionInstall
+	case switchGlobal:
+		res.Action = soft.ActionUpdate
+	default:
+		res.Action, res.Result, res.Detail = soft.ActionSkip, "ok", "all versions installed"
+		return res
+	}
+	res.Detail = strings.Join(reasons, "; ")
+	if check {
+		res.Result = resultFor(res.Action, check)
+		return res
+	}
+
+	ui.Info("[sync] %s: %s (%s)", target.Section, res.Action, res.Detail)
+	for _, v := range missing {
+		if err := m.Install(ctx, v); err != nil {
+			res.Result, res.Detail = "failed", err.Error()
+			return res
+		}
+	}
+	// Install 在安装 global 版本时会自动激活，这里再次确认
+	if global != "" {
+		if current, err = m.Current(ctx); err == nil && !language.Matches(global, current) {
+			err = m.Active(ctx, global)
+		}
+		if err != nil {
+			res.Result, res.Detail = "failed", err.Error()
+			return res
+		}
+	}
+	res.Result = "ok"
+	return res
+}
+
+func resultFor(action soft.Action, check bool) string {
+	if check && action != soft.ActionSkip {
+		return "planned"
+	}
+	return "ok"
+}
+
+// printSyncSummary 输出同步汇总表
+func printSyncSummary(ui ui.UI, results []syncResult) {
+	ui.Println("")
+	ui.Println("%-12s %-10s %-10s %s", "SECTION", "ACTION", "RESULT", "DETAIL")
+	for _, r := range results {
+		ui.Println("%-12s %-10s %-10s %s", r.Name, r.Action, r.Result, r.Detail)
+	}
+}
@@ -0,0 +1,107 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/manager/tool"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// NewToolPlugin 管理 config.yml 中 tools 声明的 GitHub release 工具
+func NewToolPlugin(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tool",
+		Short: "Manage command line tools released on GitHub (install, update, remove, list)",
+	}
+	cmd.AddCommand(
+		newToolActionCmd(ui, cfg, "install", "Install tools declared in config (defaults to all)", (*tool.Installer).Install),
+		newToolActionCmd(ui, cfg, "update", "Update tools to the configured or latest version (defaults to all)", (*tool.Installer).Update),
+		newToolActionCmd(ui, cfg, "remove", "Remove installed tools (defaults to all)", (*tool.Installer).Remove),
+		newToolListCmd(ui, cfg),
+	)
+	return cmd
+}
+
+// toolContext 返回带干跑、离线设置的 context
+func toolContext(cfg *config.GlobalConfig) context.Context {
+	ctx := cacheContext(cfg)
+	if cfg.Common.Offline {
+		ctx = utils.WithOffline(ctx)
+	}
+	return ctx
+}
+
+// selectTools 返回参数指定的工具，未指定时返回全部
+func selectTools(cfg *config.GlobalConfig, names []string) ([]*config.ToolConfig, error) {
+	if len(cfg.Tools) == 0 {
+		return nil, fmt.Errorf("no tools declared in config")
+	}
+	if len(names) == 0 {
+		return cfg.Tools, nil
+	}
+	tools := make([]*config.ToolConfig, 0, len(names))
+	for _, name := range names {
+		t, err := tool.Find(cfg.Tools, name)
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, t)
+	}
+	return tools, nil
+}
+
+// newToolActionCmd 生成对一组工具依次执行 action 的命令，单个工具失败不影响其他工具
+func newToolActionCmd(ui ui.UI, cfg *config.GlobalConfig, name, short string, action func(*tool.Installer, context.Context, *config.ToolConfig) error) *cobra.Command {
+	return &cobra.Command{
+		Use:   name + " [tool...]",
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tools, err := selectTools(cfg, args)
+			if err != nil {
+				return err
+			}
+			ctx := toolContext(cfg)
+			defer printDryRun(ctx, ui)
+
+			installer := &tool.Installer{UI: ui, Global: cfg.Common}
+			var failed []string
+			for _, t := range tools {
+				if err := action(installer, ctx, t); err != nil {
+					ui.Error("%s %s failed: %v", name, t.Name, err)
+					failed = append(failed, t.Name)
+				}
+			}
+			if len(failed) > 0 {
+				return fmt.Errorf("%s failed for: %s", name, strings.Join(failed, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+func newToolListCmd(ui ui.UI, cfg *config.GlobalConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List declared tools and their installed versions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			installer := &tool.Installer{UI: ui, Global: cfg.Common}
+			ui.Println("%-16s %-32s %-12s %-12s %s", "NAME", "REPO", "CONFIGURED", "INSTALLED", "BINARIES")
+			for _, t := range cfg.Tools {
+				installed := installer.InstalledVersion(t.Name)
+				if installed == "" {
+					installed = "-"
+				}
+				ui.Println("%-16s %-32s %-12s %-12s %s", t.Name, t.Repo, t.Version, installed, strings.Join(t.Binaries, ", "))
+			}
+			ui.Info("%d tool(s), binaries are linked into %s", len(cfg.Tools), installer.BinDir())
+			return nil
+		},
+	}
+}
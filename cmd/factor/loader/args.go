@@ -0,0 +1,99 @@
+package loader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bookandmusic/dev-tools/internal/manager/script"
+)
+
+// validateArgs checks the positional argument declarations of a command
+func validateArgs(args []Arg) error {
+	seen := map[string]bool{}
+	optional := false
+	for i, arg := range args {
+		if arg.Name == "" {
+			return fmt.Errorf("arg #%d: name is required", i+1)
+		}
+		if seen[arg.Name] {
+			return fmt.Errorf("duplicate arg %s", arg.Name)
+		}
+		seen[arg.Name] = true
+		if arg.Variadic && i != len(args)-1 {
+			return fmt.Errorf("arg %s: only the last arg can be variadic", arg.Name)
+		}
+		if arg.Required && optional {
+			return fmt.Errorf("arg %s: required args must come before optional ones", arg.Name)
+		}
+		optional = optional || !arg.Required
+	}
+	return nil
+}
+
+// argsUsage renders the args for the Use line, e.g. " <host> [port] [extra...]"
+func argsUsage(args []Arg) string {
+	var b strings.Builder
+	for _, arg := range args {
+		name := arg.Name
+		if arg.Variadic {
+			name += "..."
+		}
+		if arg.Required {
+			fmt.Fprintf(&b, " <%s>", name)
+		} else {
+			fmt.Fprintf(&b, " [%s]", name)
+		}
+	}
+	return b.String()
+}
+
+// argsHelp appends an Arguments section with the arg descriptions to the long help
+func argsHelp(usage string, args []Arg) string {
+	if len(args) == 0 {
+		return ""
+	}
+	width := 0
+	for _, arg := range args {
+		width = max(width, len(arg.Name))
+	}
+	var b strings.Builder
+	b.WriteString(usage)
+	b.WriteString("\n\nArguments:")
+	for _, arg := range args {
+		fmt.Fprintf(&b, "\n  %-*s  %s", width, arg.Name, arg.Description)
+	}
+	return strings.TrimPrefix(b.String(), "\n\n")
+}
+
+// argsValidator returns the cobra validator for the declared number of args
+func argsValidator(args []Arg) cobra.PositionalArgs {
+	required := 0
+	for _, arg := range args {
+		if arg.Required {
+			required++
+		}
+	}
+	if args[len(args)-1].Variadic {
+		return cobra.MinimumNArgs(required)
+	}
+	return cobra.RangeArgs(required, len(args))
+}
+
+// bindArgs names the given positional values after their declarations; a variadic arg takes
+// all remaining values and args that were not given are left out
+func bindArgs(specs []Arg, args []string) []script.NamedArg {
+	named := make([]script.NamedArg, 0, len(specs))
+	for i, spec := range specs {
+		if i >= len(args) {
+			break
+		}
+		values := args[i : i+1]
+		if spec.Variadic {
+			values = args[i:]
+		}
+		named = append(named, script.NamedArg{Name: spec.Name, Values: values, Variadic: spec.Variadic})
+	}
+	return named
+}
@@ -0,0 +1,116 @@
+package loader
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bookandmusic/dev-tools/internal/manager/script"
+)
+
+func TestValidateArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []Arg
+		wantErr string
+	}{
+		{name: "required then optional", args: []Arg{{Name: "host", Required: true}, {Name: "port"}}},
+		{name: "variadic last", args: []Arg{{Name: "host", Required: true}, {Name: "extra", Variadic: true}}},
+		{name: "missing name", args: []Arg{{Name: "host"}, {}}, wantErr: "arg #2: name is required"},
+		{name: "duplicate", args: []Arg{{Name: "host"}, {Name: "host"}}, wantErr: "duplicate arg host"},
+		{name: "variadic not last", args: []Arg{{Name: "files", Variadic: true}, {Name: "dest"}}, wantErr: "only the last arg can be variadic"},
+		{name: "required after optional", args: []Arg{{Name: "port"}, {Name: "host", Required: true}}, wantErr: "required args must come before optional ones"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateArgs(tt.args)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateArgs error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateArgs error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestArgsUsage(t *testing.T) {
+	args := []Arg{{Name: "host", Required: true}, {Name: "port"}, {Name: "extra", Variadic: true}}
+	if got, want := argsUsage(args), " <host> [port] [extra...]"; got != want {
+		t.Errorf("argsUsage = %q, want %q", got, want)
+	}
+}
+
+func TestArgsValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []Arg
+		given   int
+		wantErr bool
+	}{
+		{name: "fixed exact", specs: []Arg{{Name: "a", Required: true}, {Name: "b"}}, given: 2},
+		{name: "fixed optional omitted", specs: []Arg{{Name: "a", Required: true}, {Name: "b"}}, given: 1},
+		{name: "fixed missing required", specs: []Arg{{Name: "a", Required: true}, {Name: "b"}}, given: 0, wantErr: true},
+		{name: "fixed too many", specs: []Arg{{Name: "a", Required: true}, {Name: "b"}}, given: 3, wantErr: true},
+		{name: "variadic many", specs: []Arg{{Name: "a", Required: true}, {Name: "rest", Variadic: true}}, given: 5},
+		{name: "variadic missing required", specs: []Arg{{Name: "a", Required: true}, {Name: "rest", Variadic: true}}, given: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := argsValidator(tt.specs)(nil, make([]string, tt.given))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validator(%d args) error = %v, wantErr %v", tt.given, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBindArgs(t *testing.T) {
+	tests := []struct {
+		name  string
+		specs []Arg
+		args  []string
+		want  []script.NamedArg
+	}{
+		{
+			name:  "all given",
+			specs: []Arg{{Name: "host", Required: true}, {Name: "port"}},
+			args:  []string{"example.com", "22"},
+			want: []script.NamedArg{
+				{Name: "host", Values: []string{"example.com"}},
+				{Name: "port", Values: []string{"22"}},
+			},
+		},
+		{
+			name:  "optional omitted",
+			specs: []Arg{{Name: "host", Required: true}, {Name: "port"}},
+			args:  []string{"example.com"},
+			want:  []script.NamedArg{{Name: "host", Values: []string{"example.com"}}},
+		},
+		{
+			name:  "variadic takes the rest",
+			specs: []Arg{{Name: "dest", Required: true}, {Name: "files", Variadic: true}},
+			args:  []string{"/tmp", "a", "b", "c"},
+			want: []script.NamedArg{
+				{Name: "dest", Values: []string{"/tmp"}},
+				{Name: "files", Values: []string{"a", "b", "c"}, Variadic: true},
+			},
+		},
+		{
+			name:  "variadic omitted",
+			specs: []Arg{{Name: "dest", Required: true}, {Name: "files", Variadic: true}},
+			args:  []string{"/tmp"},
+			want:  []script.NamedArg{{Name: "dest", Values: []string{"/tmp"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bindArgs(tt.specs, tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bindArgs = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -21,7 +21,7 @@ func CreateCommandTree(basePath string, meta *PluginMeta, executor script.Plugin
 		}
 	} else {
 		path := executor.ScriptPath(basePath, meta.Name)
-		root.RunE = makeRunE(path, executor)
+		root.RunE = makeRunE(path, executor, Command{})
 	}
 
 	return root
@@ -30,17 +30,21 @@ func CreateCommandTree(basePath string, meta *PluginMeta, executor script.Plugin
 func buildCommand(basePath string, pathParts []string, cmdDef Command, executor script.PluginExecutor) *cobra.Command {
 	cmdName := pathParts[len(pathParts)-1]
 	cmd := &cobra.Command{
-		Use:   cmdName,
+		Use:   cmdName + argsUsage(cmdDef.Args),
 		Short: cmdDef.Usage,
+		Long:  argsHelp(cmdDef.Usage, cmdDef.Args),
+	}
+	if len(cmdDef.Args) > 0 {
+		cmd.Args = argsValidator(cmdDef.Args)
 	}
 
-	// 添加 flags
+	// 添加 flags，类型与校验规则来自 meta.yml
 	for _, opt := range cmdDef.Options {
-		cmd.Flags().StringP(opt.Name, opt.Short, opt.Value, opt.Description)
+		opt.register(cmd)
 	}
 
 	scriptPath := executor.ScriptPath(basePath, pathParts...)
-	cmd.RunE = makeRunE(scriptPath, executor)
+	cmd.RunE = makeRunE(scriptPath, executor, cmdDef)
 
 	for subName, subCmdDef := range cmdDef.Subcommands {
 		newPath := append(pathParts, subName)
@@ -51,11 +55,14 @@ func buildCommand(basePath string, pathParts []string, cmdDef Command, executor
 	return cmd
 }
 
-func makeRunE(scriptPath string, executor script.PluginExecutor) func(cmd *cobra.Command, args []string) error {
+func makeRunE(scriptPath string, executor script.PluginExecutor, cmdDef Command) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
 			return executor.NotFoundError(scriptPath)
 		}
-		return executor.Exec(scriptPath, cmd, args)
+		if err := applyOptions(cmd, cmdDef.Options); err != nil {
+			return err
+		}
+		return executor.Exec(scriptPath, cmd, args, bindArgs(cmdDef.Args, args))
 	}
 }
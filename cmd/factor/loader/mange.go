@@ -3,31 +3,59 @@ package loader
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/bookandmusic/dev-tools/cmd/plugin"
+	"github.com/bookandmusic/dev-tools/internal/config"
 	"github.com/bookandmusic/dev-tools/internal/manager/script"
 	"github.com/bookandmusic/dev-tools/internal/ui"
 )
 
-func LoadPluginsFromLoader(rootDir string, ui ui.UI) {
-	pluginDir := filepath.Join(rootDir, "plugins")
+func LoadPluginsFromLoader(ui ui.UI, cfg *config.GlobalConfig) {
+	_ = walkPlugins(PluginDir(cfg.Common), func(path string, meta *PluginMeta) {
+		if err := meta.Validate(); err != nil {
+			ui.Warning("Skipping plugin %s: %v", path, err)
+			return
+		}
+		executor, err := script.NewExecutor(meta.Type, ui, script.Options{
+			Interpreter: meta.Interpreter,
+			PluginDir:   path,
+			Global:      cfg.Common,
+		})
+		if err != nil {
+			ui.Warning("Skipping plugin %s: %v", path, err)
+			return
+		}
+		plugin.Register(CreateCommandTree(path, meta, executor))
+	})
+}
+
+// CountPlugins returns the number of plugins under pluginDir, counted the same way they are loaded
+func CountPlugins(pluginDir string) int {
+	count := 0
+	_ = walkPlugins(pluginDir, func(string, *PluginMeta) { count++ })
+	return count
+}
 
+// walkPlugins calls fn for every directory under pluginDir that holds a readable meta.yml.
+// Hidden directories (staging dirs, .git) are skipped
+func walkPlugins(pluginDir string, fn func(path string, meta *PluginMeta)) error {
 	info, err := os.Stat(pluginDir)
 	if err != nil || !info.IsDir() {
-		return
+		return nil
 	}
-
-	_ = filepath.Walk(pluginDir, func(path string, info os.FileInfo, err error) error {
+	return filepath.Walk(pluginDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() && path != pluginDir && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
 		meta, metaErr := LoadPluginMeta(path, info)
 		if metaErr != nil {
 			return nil
 		}
-		switch meta.Type {
-		case "shell":
-			plugin.Register(CreateCommandTree(path, meta, script.NewShellExecutor(ui)))
-		case "ansible":
-			plugin.Register(CreateCommandTree(path, meta, script.NewAnsibleExecutor(ui)))
-		}
+		fn(path, meta)
 		return nil
 	})
 }
@@ -0,0 +1,75 @@
+package loader
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// manifestFile records where the plugins in the plugins dir were installed from
+const manifestFile = "manifest.json"
+
+// Source kinds a plugin can be installed from
+const (
+	SourceGit     = "git"
+	SourceDir     = "dir"
+	SourceTarball = "tarball"
+)
+
+// ManifestEntry describes an installed plugin package
+type ManifestEntry struct {
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Version     string    `json:"version,omitempty"`
+	Source      string    `json:"source"`
+	Kind        string    `json:"kind"`
+	Commit      string    `json:"commit,omitempty"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// Manifest is the content of <plugins dir>/manifest.json, keyed by plugin name
+type Manifest struct {
+	path    string
+	Plugins map[string]ManifestEntry `json:"plugins"`
+}
+
+// OpenManifest reads the manifest in the plugins dir, returning an empty one when it does not exist
+func OpenManifest(pluginDir string) (*Manifest, error) {
+	m := &Manifest{
+		path:    filepath.Join(pluginDir, manifestFile),
+		Plugins: map[string]ManifestEntry{},
+	}
+	if err := utils.ReadJSONFile(m.path, m); err != nil {
+		return nil, err
+	}
+	if m.Plugins == nil {
+		m.Plugins = map[string]ManifestEntry{}
+	}
+	return m, nil
+}
+
+// Get returns the entry of a plugin
+func (m *Manifest) Get(name string) (ManifestEntry, bool) {
+	e, ok := m.Plugins[name]
+	return e, ok
+}
+
+// Set records a plugin, using the current time when InstalledAt is zero
+func (m *Manifest) Set(e ManifestEntry) {
+	if e.InstalledAt.IsZero() {
+		e.InstalledAt = time.Now()
+	}
+	m.Plugins[e.Name] = e
+}
+
+// Delete removes the entry of a plugin
+func (m *Manifest) Delete(name string) {
+	delete(m.Plugins, name)
+}
+
+// Save writes the manifest back; in dry-run mode only the planned content is recorded
+func (m *Manifest) Save(ctx context.Context) error {
+	return utils.WriteJSONFile(ctx, m.path, m)
+}
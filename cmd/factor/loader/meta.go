@@ -4,16 +4,20 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	yaml "gopkg.in/yaml.v3"
 )
 
 // PluginMeta represents the metadata of a plugin defined in meta.yml
 type PluginMeta struct {
-	Name        string             `yaml:"name"`
-	Description string             `yaml:"description"`
-	Type        string             `yaml:"type"`
-	Version     string             `yaml:"version"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Type        string `yaml:"type"`
+	Version     string `yaml:"version"`
+	// Interpreter runs the command files of exec plugins, e.g. "python3"; empty means run them directly
+	Interpreter string             `yaml:"interpreter,omitempty"`
 	Commands    map[string]Command `yaml:"commands"`
 }
 
@@ -22,15 +26,72 @@ type Command struct {
 	Description string             `yaml:"description"`
 	Usage       string             `yaml:"usage"`
 	Options     []Option           `yaml:"options,omitempty"`
+	Args        []Arg              `yaml:"args,omitempty"`
 	Subcommands map[string]Command `yaml:"subcommands,omitempty"`
 }
 
+// Arg represents a named positional argument. Required args come first,
+// and only the last arg may be variadic (it then takes all remaining values)
+type Arg struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required,omitempty"`
+	Variadic    bool   `yaml:"variadic,omitempty"`
+}
+
 // Option represents a command-line option
 type Option struct {
 	Name        string `yaml:"name"`
 	Short       string `yaml:"short,omitempty"`
 	Description string `yaml:"description"`
-	Value       string `yaml:"value,omitempty"`
+	// Value is the default value, written as on the command line (comma separated for stringSlice)
+	Value string `yaml:"value,omitempty"`
+	// Type is one of string (default), bool, int, float, duration or stringSlice
+	Type     string `yaml:"type,omitempty"`
+	Required bool   `yaml:"required,omitempty"`
+	// Choices restricts the value (every element for stringSlice) to a fixed set
+	Choices []string `yaml:"choices,omitempty"`
+	// Env is read when the flag is not given on the command line
+	Env    string `yaml:"env,omitempty"`
+	Hidden bool   `yaml:"hidden,omitempty"`
+}
+
+// Validate checks every option declared by the plugin's commands
+func (m *PluginMeta) Validate() error {
+	for name, cmd := range m.Commands {
+		if err := cmd.validate(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate checks the options of a command and its subcommands; path is used in error messages
+func (c Command) validate(path string) error {
+	seen := map[string]bool{}
+	for _, opt := range c.Options {
+		if err := opt.Validate(); err != nil {
+			return fmt.Errorf("command %s: %w", path, err)
+		}
+		if seen[opt.Name] {
+			return fmt.Errorf("command %s: duplicate option %s", path, opt.Name)
+		}
+		seen[opt.Name] = true
+	}
+	if err := validateArgs(c.Args); err != nil {
+		return fmt.Errorf("command %s: %w", path, err)
+	}
+	for _, arg := range c.Args {
+		if seen[arg.Name] {
+			return fmt.Errorf("command %s: arg %s has the same name as an option", path, arg.Name)
+		}
+	}
+	for name, sub := range c.Subcommands {
+		if err := sub.validate(path + " " + name); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func LoadPluginMeta(pluginPath string, info os.FileInfo) (*PluginMeta, error) {
@@ -52,3 +113,30 @@ func LoadPluginMeta(pluginPath string, info os.FileInfo) (*PluginMeta, error) {
 	}
 	return &meta, nil
 }
+
+// CommandTree renders the plugin's commands as indented lines with their args and usage
+func (m *PluginMeta) CommandTree() []string {
+	return commandTree(m.Commands, 1)
+}
+
+// commandTree renders commands sorted by name, indenting subcommands by depth
+func commandTree(commands map[string]Command, depth int) []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines []string
+	indent := strings.Repeat("  ", depth)
+	for _, name := range names {
+		cmd := commands[name]
+		line := indent + name + argsUsage(cmd.Args)
+		if cmd.Usage != "" {
+			line += "  " + cmd.Usage
+		}
+		lines = append(lines, line)
+		lines = append(lines, commandTree(cmd.Subcommands, depth+1)...)
+	}
+	return lines
+}
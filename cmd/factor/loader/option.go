@@ -0,0 +1,218 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Supported option types in meta.yml; an empty type means string
+const (
+	OptionString      = "string"
+	OptionBool        = "bool"
+	OptionInt         = "int"
+	OptionFloat       = "float"
+	OptionDuration    = "duration"
+	OptionStringSlice = "stringSlice"
+)
+
+// optionType returns the declared type, defaulting to string
+func (o Option) optionType() string {
+	if o.Type == "" {
+		return OptionString
+	}
+	return o.Type
+}
+
+// Validate checks the option declaration itself: known type, a parsable default and choices
+func (o Option) Validate() error {
+	if o.Name == "" {
+		return fmt.Errorf("option name is required")
+	}
+	if len(o.Short) > 1 {
+		return fmt.Errorf("option %s: short must be a single character, got %q", o.Name, o.Short)
+	}
+	switch o.optionType() {
+	case OptionString, OptionStringSlice, OptionInt, OptionFloat, OptionDuration:
+	case OptionBool:
+		if len(o.Choices) > 0 {
+			return fmt.Errorf("option %s: choices are not supported for bool options", o.Name)
+		}
+	default:
+		return fmt.Errorf("option %s: unknown type %q", o.Name, o.Type)
+	}
+	for _, choice := range o.Choices {
+		if err := o.parse(choice); err != nil {
+			return fmt.Errorf("option %s: invalid choice %q: %w", o.Name, choice, err)
+		}
+	}
+	if o.Value != "" {
+		if err := o.parse(o.Value); err != nil {
+			return fmt.Errorf("option %s: invalid default %q: %w", o.Name, o.Value, err)
+		}
+		if err := o.checkChoices(o.Value); err != nil {
+			return fmt.Errorf("option %s: default %w", o.Name, err)
+		}
+	}
+	return nil
+}
+
+// parse checks that a raw value can be converted to the option type
+func (o Option) parse(raw string) error {
+	var err error
+	switch o.optionType() {
+	case OptionBool:
+		_, err = strconv.ParseBool(raw)
+	case OptionInt:
+		_, err = strconv.Atoi(raw)
+	case OptionFloat:
+		_, err = strconv.ParseFloat(raw, 64)
+	case OptionDuration:
+		_, err = time.ParseDuration(raw)
+	}
+	return err
+}
+
+// normalize returns the value in the form pflag prints it (e.g. "1m" becomes "1m0s", "0.50" becomes "0.5"),
+// so choices written in meta.yml compare equal to parsed flag values
+func (o Option) normalize(raw string) string {
+	switch o.optionType() {
+	case OptionInt:
+		if n, err := strconv.Atoi(raw); err == nil {
+			return strconv.Itoa(n)
+		}
+	case OptionFloat:
+		if f, err := strconv.ParseFloat(raw, 64); err == nil {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	case OptionDuration:
+		if d, err := time.ParseDuration(raw); err == nil {
+			return d.String()
+		}
+	}
+	return raw
+}
+
+// checkChoices checks a value (every element for stringSlice) against the allowed choices
+func (o Option) checkChoices(raw string) error {
+	if len(o.Choices) == 0 {
+		return nil
+	}
+	values := []string{raw}
+	if o.optionType() == OptionStringSlice {
+		values = splitList(raw)
+	}
+	for _, v := range values {
+		if !slices.ContainsFunc(o.Choices, func(c string) bool { return o.normalize(c) == o.normalize(v) }) {
+			return fmt.Errorf("%q is not one of: %s", v, strings.Join(o.Choices, ", "))
+		}
+	}
+	return nil
+}
+
+// usage appends choices and the env fallback to the description shown in --help
+func (o Option) usage() string {
+	usage := o.Description
+	if len(o.Choices) > 0 {
+		usage += fmt.Sprintf(" (one of: %s)", strings.Join(o.Choices, ", "))
+	}
+	if o.Env != "" {
+		usage += fmt.Sprintf(" [$%s]", o.Env)
+	}
+	if o.Required {
+		usage += " (required)"
+	}
+	return usage
+}
+
+// register adds the option to the flag set with the pflag type matching its declaration.
+// Validate must have passed, so parse errors of the default are ignored here
+func (o Option) register(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	usage := o.usage()
+	switch o.optionType() {
+	case OptionBool:
+		def, _ := strconv.ParseBool(o.Value)
+		flags.BoolP(o.Name, o.Short, def, usage)
+	case OptionInt:
+		def, _ := strconv.Atoi(o.Value)
+		flags.IntP(o.Name, o.Short, def, usage)
+	case OptionFloat:
+		def, _ := strconv.ParseFloat(o.Value, 64)
+		flags.Float64P(o.Name, o.Short, def, usage)
+	case OptionDuration:
+		def, _ := time.ParseDuration(o.Value)
+		flags.DurationP(o.Name, o.Short, def, usage)
+	case OptionStringSlice:
+		flags.StringSliceP(o.Name, o.Short, splitList(o.Value), usage)
+	default:
+		flags.StringP(o.Name, o.Short, o.Value, usage)
+	}
+	if o.Hidden {
+		_ = flags.MarkHidden(o.Name)
+	}
+	if len(o.Choices) > 0 {
+		choices := o.Choices
+		_ = cmd.RegisterFlagCompletionFunc(o.Name, func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+			return choices, cobra.ShellCompDirectiveNoFileComp
+		})
+	}
+}
+
+// applyOptions fills unset flags from their env fallback, then checks required options and the choices of set flags.
+// Values taken from env are marked as changed so executors pass them on like command line flags
+func applyOptions(cmd *cobra.Command, options []Option) error {
+	flags := cmd.Flags()
+	for _, o := range options {
+		f := flags.Lookup(o.Name)
+		if f == nil {
+			continue
+		}
+		if !f.Changed && o.Env != "" {
+			if raw, ok := os.LookupEnv(o.Env); ok && raw != "" {
+				if err := flags.Set(o.Name, raw); err != nil {
+					return fmt.Errorf("invalid value %q for --%s from $%s: %w", raw, o.Name, o.Env, err)
+				}
+			}
+		}
+		if o.Required && !f.Changed {
+			if o.Env != "" {
+				return fmt.Errorf("required flag --%s (or $%s) not set", o.Name, o.Env)
+			}
+			return fmt.Errorf("required flag --%s not set", o.Name)
+		}
+		// Only values given on the command line or taken from env are checked; a declared default
+		// is checked by Validate, and an unset option without default keeps its zero value
+		if v := flagString(f); f.Changed && v != "" {
+			if err := o.checkChoices(v); err != nil {
+				return fmt.Errorf("invalid value for --%s: %w", o.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// flagString returns the flag value as written on the command line; slices are joined with commas
+func flagString(f *pflag.Flag) string {
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		return strings.Join(sv.GetSlice(), ",")
+	}
+	return f.Value.String()
+}
+
+// splitList splits a comma separated default or env value, dropping empty items
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,198 @@
+package loader
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestOptionValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  Option
+		wantErr string
+	}{
+		{name: "string default", option: Option{Name: "host", Value: "localhost"}},
+		{name: "int default", option: Option{Name: "port", Type: OptionInt, Value: "8080"}},
+		{name: "float default", option: Option{Name: "ratio", Type: OptionFloat, Value: "0.5"}},
+		{name: "duration default", option: Option{Name: "timeout", Type: OptionDuration, Value: "30s"}},
+		{name: "bool default", option: Option{Name: "force", Type: OptionBool, Value: "true"}},
+		{name: "slice choices", option: Option{Name: "tags", Type: OptionStringSlice, Choices: []string{"a", "b"}, Value: "a,b"}},
+		{name: "missing name", option: Option{}, wantErr: "name is required"},
+		{name: "long short", option: Option{Name: "host", Short: "ho"}, wantErr: "single character"},
+		{name: "unknown type", option: Option{Name: "host", Type: "map"}, wantErr: `unknown type "map"`},
+		{name: "bad int default", option: Option{Name: "port", Type: OptionInt, Value: "http"}, wantErr: "invalid default"},
+		{name: "bad duration default", option: Option{Name: "timeout", Type: OptionDuration, Value: "30"}, wantErr: "invalid default"},
+		{name: "bad int choice", option: Option{Name: "port", Type: OptionInt, Choices: []string{"80", "https"}}, wantErr: `invalid choice "https"`},
+		{name: "bool choices", option: Option{Name: "force", Type: OptionBool, Choices: []string{"true"}}, wantErr: "not supported for bool"},
+		{name: "default outside choices", option: Option{Name: "env", Choices: []string{"dev", "prod"}, Value: "test"}, wantErr: `"test" is not one of`},
+		{name: "slice default outside choices", option: Option{Name: "tags", Type: OptionStringSlice, Choices: []string{"a", "b"}, Value: "a,c"}, wantErr: `"c" is not one of`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.option.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// runOptions registers the options on a fresh command, parses argv and applies env fallback and checks
+func runOptions(t *testing.T, options []Option, argv ...string) (*cobra.Command, error) {
+	t.Helper()
+	cmd := &cobra.Command{Use: "test"}
+	for _, o := range options {
+		if err := o.Validate(); err != nil {
+			t.Fatalf("Validate(%s) error: %v", o.Name, err)
+		}
+		o.register(cmd)
+	}
+	if err := cmd.ParseFlags(argv); err != nil {
+		return cmd, err
+	}
+	return cmd, applyOptions(cmd, options)
+}
+
+func TestApplyOptionsEnvFallback(t *testing.T) {
+	options := []Option{
+		{Name: "region", Env: "DTL_TEST_REGION", Value: "us"},
+		{Name: "replicas", Type: OptionInt, Env: "DTL_TEST_REPLICAS"},
+		{Name: "tags", Type: OptionStringSlice, Env: "DTL_TEST_TAGS"},
+	}
+
+	t.Run("env fills unset flags", func(t *testing.T) {
+		t.Setenv("DTL_TEST_REGION", "eu")
+		t.Setenv("DTL_TEST_REPLICAS", "3")
+		t.Setenv("DTL_TEST_TAGS", "a,b")
+		cmd, err := runOptions(t, options)
+		if err != nil {
+			t.Fatalf("applyOptions error: %v", err)
+		}
+		flags := cmd.Flags()
+		if got, _ := flags.GetString("region"); got != "eu" {
+			t.Errorf("region = %q, want eu", got)
+		}
+		if got, _ := flags.GetInt("replicas"); got != 3 {
+			t.Errorf("replicas = %d, want 3", got)
+		}
+		if got, _ := flags.GetStringSlice("tags"); strings.Join(got, ",") != "a,b" {
+			t.Errorf("tags = %v, want [a b]", got)
+		}
+		// values taken from env are marked as changed like command line flags
+		if !flags.Lookup("region").Changed {
+			t.Error("region not marked as changed")
+		}
+	})
+
+	t.Run("command line wins over env", func(t *testing.T) {
+		t.Setenv("DTL_TEST_REGION", "eu")
+		cmd, err := runOptions(t, options, "--region", "ap")
+		if err != nil {
+			t.Fatalf("applyOptions error: %v", err)
+		}
+		if got, _ := cmd.Flags().GetString("region"); got != "ap" {
+			t.Errorf("region = %q, want ap", got)
+		}
+	})
+
+	t.Run("empty env keeps default", func(t *testing.T) {
+		t.Setenv("DTL_TEST_REGION", "")
+		cmd, err := runOptions(t, options)
+		if err != nil {
+			t.Fatalf("applyOptions error: %v", err)
+		}
+		if got, _ := cmd.Flags().GetString("region"); got != "us" {
+			t.Errorf("region = %q, want us", got)
+		}
+	})
+
+	t.Run("invalid env value", func(t *testing.T) {
+		t.Setenv("DTL_TEST_REPLICAS", "many")
+		_, err := runOptions(t, options)
+		if err == nil || !strings.Contains(err.Error(), "$DTL_TEST_REPLICAS") {
+			t.Fatalf("applyOptions error = %v, want invalid value from $DTL_TEST_REPLICAS", err)
+		}
+	})
+}
+
+func TestApplyOptionsRequired(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+		argv    []string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "missing", options: []Option{{Name: "token", Required: true}}, wantErr: "required flag --token not set"},
+		{name: "missing with env", options: []Option{{Name: "token", Required: true, Env: "DTL_TEST_TOKEN"}}, wantErr: "required flag --token (or $DTL_TEST_TOKEN) not set"},
+		{name: "given", options: []Option{{Name: "token", Required: true}}, argv: []string{"--token", "x"}},
+		{name: "given by env", options: []Option{{Name: "token", Required: true, Env: "DTL_TEST_TOKEN"}}, env: map[string]string{"DTL_TEST_TOKEN": "x"}},
+		// a default does not satisfy required, the value must be given explicitly
+		{name: "default is not enough", options: []Option{{Name: "token", Required: true, Value: "x"}}, wantErr: "required flag --token not set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := runOptions(t, tt.options, tt.argv...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("applyOptions error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("applyOptions error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyOptionsChoices(t *testing.T) {
+	options := []Option{
+		{Name: "env", Choices: []string{"dev", "prod"}},
+		{Name: "tags", Type: OptionStringSlice, Choices: []string{"a", "b"}},
+		{Name: "workers", Type: OptionInt, Choices: []string{"2", "4"}},
+		{Name: "timeout", Type: OptionDuration, Choices: []string{"1m", "90s"}},
+		{Name: "ratio", Type: OptionFloat, Choices: []string{"0.50", "1"}},
+	}
+	tests := []struct {
+		name    string
+		argv    []string
+		wantErr string
+	}{
+		{name: "valid", argv: []string{"--env", "prod", "--tags", "a,b"}},
+		{name: "unset", argv: nil},
+		{name: "int", argv: []string{"--workers", "4"}},
+		{name: "duration normalized", argv: []string{"--timeout", "1m"}},
+		{name: "duration other spelling", argv: []string{"--timeout", "1m30s"}},
+		{name: "float normalized", argv: []string{"--ratio", "0.5"}},
+		{name: "invalid int", argv: []string{"--workers", "3"}, wantErr: `invalid value for --workers: "3" is not one of: 2, 4`},
+		{name: "invalid duration", argv: []string{"--timeout", "2m"}, wantErr: `invalid value for --timeout: "2m0s" is not one of: 1m, 90s`},
+		{name: "invalid", argv: []string{"--env", "test"}, wantErr: `invalid value for --env: "test" is not one of: dev, prod`},
+		{name: "invalid slice element", argv: []string{"--tags", "a,c"}, wantErr: `invalid value for --tags: "c" is not one of: a, b`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runOptions(t, options, tt.argv...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("applyOptions error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("applyOptions error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/manager/script"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// Packages installs, updates and removes plugin packages in <root>/plugins.
+// A source is a git URL (optionally with "#<branch or tag>"), a local directory,
+// or a .tar.gz/.tgz file or URL; the installed directory is named after meta.yml's name
+type Packages struct {
+	UI     ui.UI
+	Global *config.CommonConfig
+}
+
+// InstalledPlugin is a plugin found in the plugins dir together with its manifest entry
+type InstalledPlugin struct {
+	Path  string
+	Meta  *PluginMeta
+	Entry *ManifestEntry // nil when the plugin was copied in by hand
+}
+
+// source is a parsed install source
+type source struct {
+	raw      string
+	kind     string
+	location string
+	ref      string // git branch or tag
+}
+
+// PluginDir returns the directory plugins are loaded from
+func PluginDir(global *config.CommonConfig) string {
+	return filepath.Join(utils.ExpandAbsDir(global.RootDir), "plugins")
+}
+
+// Dir returns the plugins dir
+func (p *Packages) Dir() string {
+	return PluginDir(p.Global)
+}
+
+// parseSource works out the kind of an install source
+func parseSource(raw string) (source, error) {
+	s := source{raw: raw, location: raw}
+	lower := strings.ToLower(raw)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz"):
+		s.kind = SourceTarball
+		if !isURL(raw) {
+			s.location = utils.ExpandAbsDir(raw)
+			s.raw = s.location
+		}
+	case isGitURL(raw):
+		s.kind = SourceGit
+		s.location, s.ref, _ = strings.Cut(raw, "#")
+	default:
+		dir := utils.ExpandAbsDir(raw)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return s, fmt.Errorf("unsupported plugin source %q: expected a git URL, a directory or a .tar.gz file", raw)
+		}
+		s.kind, s.location, s.raw = SourceDir, dir, dir
+	}
+	return s, nil
+}
+
+// isURL reports whether the source is downloaded over http(s)
+func isURL(raw string) bool {
+	return strings.HasPrefix(raw, "http://") || strings.HasPrefix(raw, "https://")
+}
+
+// isGitURL reports whether the source is a git repository URL; http(s) URLs that are not tarballs are cloned
+func isGitURL(raw string) bool {
+	repo, _, _ := strings.Cut(raw, "#")
+	return strings.HasPrefix(repo, "git@") || strings.HasPrefix(repo, "ssh://") ||
+		strings.HasPrefix(repo, "git://") || strings.HasPrefix(repo, "file://") ||
+		strings.HasSuffix(repo, ".git") || isURL(repo)
+}
+
+// Install installs a plugin package from source. An existing plugin with the same name
+// is only replaced when force is set; use Update for plugins installed from a source
+func (p *Packages) Install(ctx context.Context, raw string, force bool) error {
+	src, err := parseSource(raw)
+	if err != nil {
+		return err
+	}
+	return p.install(ctx, src, "", force)
+}
+
+// Update reinstalls a plugin from the source recorded in the manifest
+func (p *Packages) Update(ctx context.Context, name string) error {
+	manifest, err := OpenManifest(p.Dir())
+	if err != nil {
+		return err
+	}
+	entry, ok := manifest.Get(name)
+	if !ok {
+		return fmt.Errorf("plugin %s has no recorded source, reinstall it with `plugin install <source> --force`", name)
+	}
+	src, err := parseSource(entry.Source)
+	if err != nil {
+		return err
+	}
+	return p.install(ctx, src, name, true)
+}
+
+// Remove deletes a plugin directory and its manifest entry
+func (p *Packages) Remove(ctx context.Context, name string) error {
+	manifest, err := OpenManifest(p.Dir())
+	if err != nil {
+		return err
+	}
+	target, err := p.pluginPath(name)
+	if err != nil {
+		return err
+	}
+	_, recorded := manifest.Get(name)
+	if !utils.PathExists(target) && !recorded {
+		return fmt.Errorf("plugin %s is not installed", name)
+	}
+
+	p.UI.Info("Removing plugin %s from %s", name, target)
+	if err := utils.RemoveAll(ctx, target); err != nil {
+		return err
+	}
+	if recorded {
+		manifest.Delete(name)
+		if err := manifest.Save(ctx); err != nil {
+			return err
+		}
+	}
+	p.UI.Success("Plugin %s removed", name)
+	return nil
+}
+
+// List returns the plugins in the plugins dir sorted by name
+func (p *Packages) List() ([]InstalledPlugin, error) {
+	manifest, err := OpenManifest(p.Dir())
+	if err != nil {
+		return nil, err
+	}
+	var plugins []InstalledPlugin
+	err = walkPlugins(p.Dir(), func(path string, meta *PluginMeta) {
+		ip := InstalledPlugin{Path: path, Meta: meta}
+		if e, ok := manifest.Get(meta.Name); ok {
+			ip.Entry = &e
+		}
+		plugins = append(plugins, ip)
+	})
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Meta.Name < plugins[j].Meta.Name })
+	return plugins, err
+}
+
+// pluginPath returns the install directory of a plugin, rejecting names that escape the plugins dir
+func (p *Packages) pluginPath(name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid plugin name %q", name)
+	}
+	return filepath.Join(p.Dir(), name), nil
+}
+
+// install stages the source, validates its meta.yml and moves it into the plugins dir.
+// expect is the plugin name being updated; it must not change
+func (p *Packages) install(ctx context.Context, src source, expect string, force bool) error {
+	if (src.kind == SourceGit || isURL(src.location)) && utils.IsOffline(ctx) {
+		return fmt.Errorf("install plugin from %s: %w", src.raw, utils.ErrOffline)
+	}
+	if d := utils.DryRunFromContext(ctx); d != nil && src.kind != SourceDir {
+		// nothing is fetched in dry-run mode, so meta.yml cannot be read
+		d.Record("plugin", src.raw, fmt.Sprintf("install %s plugin into %s", src.kind, p.Dir()))
+		return nil
+	}
+
+	root, commit, cleanup, err := p.stage(ctx, src)
+	if cleanup != nil {
+		defer cleanup()
+	}
+	if err != nil {
+		return err
+	}
+	meta, err := readPluginMeta(root)
+	if err != nil {
+		return err
+	}
+	if expect != "" && meta.Name != expect {
+		return fmt.Errorf("source %s now provides plugin %s instead of %s", src.raw, meta.Name, expect)
+	}
+	target, err := p.pluginPath(meta.Name)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := OpenManifest(p.Dir())
+	if err != nil {
+		return err
+	}
+	old, recorded := manifest.Get(meta.Name)
+	if utils.PathExists(target) && !force {
+		if recorded {
+			return fmt.Errorf("plugin %s is already installed, run `plugin update %s`", meta.Name, meta.Name)
+		}
+		return fmt.Errorf("plugin %s already exists in %s, use --force to replace it", meta.Name, target)
+	}
+
+	if err := p.replace(ctx, root, target); err != nil {
+		return err
+	}
+	manifest.Set(ManifestEntry{
+		Name:    meta.Name,
+		Type:    meta.Type,
+		Version: meta.Version,
+		Source:  src.raw,
+		Kind:    src.kind,
+		Commit:  commit,
+	})
+	if err := manifest.Save(ctx); err != nil {
+		return err
+	}
+
+	switch {
+	case recorded && old.Version != meta.Version:
+		p.UI.Success("Plugin %s updated: %s -> %s", meta.Name, displayVersion(old.Version), displayVersion(meta.Version))
+	case recorded:
+		p.UI.Success("Plugin %s %s reinstalled from %s", meta.Name, displayVersion(meta.Version), src.raw)
+	default:
+		p.UI.Success("Plugin %s %s installed into %s", meta.Name, displayVersion(meta.Version), target)
+	}
+	return nil
+}
+
+// stage fetches the source into a hidden directory inside the plugins dir and returns the
+// directory holding meta.yml; in dry-run mode a directory source is used in place
+func (p *Packages) stage(ctx context.Context, src source) (root, commit string, cleanup func(), err error) {
+	if utils.DryRunFromContext(ctx) != nil {
+		return src.location, "", nil, nil
+	}
+	if err := os.MkdirAll(p.Dir(), 0o700); err != nil {
+		return "", "", nil, err
+	}
+	staging, err := os.MkdirTemp(p.Dir(), ".staging-")
+	if err != nil {
+		return "", "", nil, err
+	}
+	cleanup = func() { _ = os.RemoveAll(staging) }
+	dir := filepath.Join(staging, "src")
+
+	switch src.kind {
+	case SourceGit:
+		if err := utils.CloneRepoRef(ctx, p.UI, src.location, src.ref, dir, p.Global.HttpProxy, p.Global.GithubProxy); err != nil {
+			return "", "", cleanup, err
+		}
+		commit, _ = utils.CommandOutput(ctx, "git", "-C", dir, "rev-parse", "HEAD")
+		if err := os.RemoveAll(filepath.Join(dir, ".git")); err != nil {
+			return "", "", cleanup, err
+		}
+	case SourceDir:
+		if err := utils.CopyDirWithProgress(ctx, src.location, dir, p.UI); err != nil {
+			return "", "", cleanup, err
+		}
+	case SourceTarball:
+		archive := src.location
+		if isURL(src.location) {
+			archive = filepath.Join(staging, filepath.Base(src.location))
+			if err := utils.DownloadWithMirrors(ctx, p.UI, []string{src.location}, archive, p.Global.DownloadOptions("")); err != nil {
+				return "", "", cleanup, err
+			}
+		}
+		// the extractor rejects entries and links that would land outside dir
+		if err := utils.ExtractTarGzWithProgress(ctx, p.UI, archive, dir, 0); err != nil {
+			return "", "", cleanup, err
+		}
+	}
+
+	if root, err = findPluginRoot(dir); err != nil {
+		return "", "", cleanup, err
+	}
+	return root, commit, cleanup, checkLinks(root)
+}
+
+// checkLinks rejects symlinks in a staged plugin that point outside of it, so an installed
+// plugin cannot expose or overwrite files elsewhere through its own directory
+func checkLinks(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			return err
+		}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		resolved := filepath.Join(filepath.Dir(path), target)
+		if filepath.IsAbs(target) || (resolved != root && !utils.WithinDir(root, resolved)) {
+			rel, _ := filepath.Rel(root, path)
+			return fmt.Errorf("plugin contains a symlink pointing outside of it: %s -> %s", rel, target)
+		}
+		return nil
+	})
+}
+
+// replace moves the staged plugin to target, keeping the old copy until the move succeeded
+func (p *Packages) replace(ctx context.Context, staged, target string) error {
+	if utils.DryRunFromContext(ctx) != nil {
+		if utils.PathExists(target) {
+			if err := utils.RemoveAll(ctx, target); err != nil {
+				return err
+			}
+		}
+		return utils.CopyDirWithProgress(ctx, staged, target, p.UI)
+	}
+
+	backup := filepath.Join(p.Dir(), "."+filepath.Base(target)+".old")
+	_ = os.RemoveAll(backup)
+	if utils.PathExists(target) {
+		if err := os.Rename(target, backup); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(staged, target); err != nil {
+		if utils.PathExists(backup) {
+			_ = os.Rename(backup, target)
+		}
+		return err
+	}
+	return os.RemoveAll(backup)
+}
+
+// findPluginRoot returns dir when it holds meta.yml, or its only subdirectory that does
+// (archives and repositories often wrap the plugin in a top-level folder)
+func findPluginRoot(dir string) (string, error) {
+	if utils.PathExists(filepath.Join(dir, "meta.yml")) {
+		return dir, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			dirs = append(dirs, e.Name())
+		}
+	}
+	if len(dirs) == 1 && utils.PathExists(filepath.Join(dir, dirs[0], "meta.yml")) {
+		return filepath.Join(dir, dirs[0]), nil
+	}
+	return "", errors.New("no meta.yml found in plugin source")
+}
+
+// readPluginMeta loads and validates meta.yml of a plugin directory
+func readPluginMeta(dir string) (*PluginMeta, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := LoadPluginMeta(dir, info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read meta.yml: %w", err)
+	}
+	if meta.Name == "" {
+		return nil, errors.New("meta.yml has no name")
+	}
+	if err := meta.Validate(); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(script.Types(), meta.Type) {
+		return nil, fmt.Errorf("unknown plugin type %q, supported: %s", meta.Type, strings.Join(script.Types(), ", "))
+	}
+	return meta, nil
+}
+
+// displayVersion shows "-" for plugins without a version
+func displayVersion(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return v
+}
@@ -19,6 +19,9 @@ var (
 	configFile       string
 	configFileChange bool
 	debug            bool
+	dryRun           bool
+	offline          bool
+	bundleFile       string
 
 	rootCmd = &cobra.Command{
 		Use:   "dev-tools",
@@ -29,6 +32,9 @@ var (
 func init() {
 	// 定义全局 flag
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Enable debug mode")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print planned actions (commands, file diffs, downloads) without executing them")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Never touch the network, install only from the cache or --bundle")
+	rootCmd.PersistentFlags().StringVar(&bundleFile, "bundle", "", "Install from a `file` created by bundle create (implies --offline)")
 	rootCmd.PersistentFlags().StringVarP(&rootDir, "root-dir", "r", "~/.tools", "tools root directory")
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "config.yml", "Load configuration from FILE")
 	// 预解析全局 flags（必须在命令注册前调用，否则 cobra 会报错）
@@ -54,15 +60,21 @@ func init() {
 		ui.Debug("Failed to load config: %v", err)
 	}
 	if cfg == nil {
-		cfg = &config.GlobalConfig{Common: &config.CommonConfig{}}
+		cfg = &config.GlobalConfig{}
+	}
+	if cfg.Common == nil {
+		cfg.Common = &config.CommonConfig{}
 	}
 	workdir := utils.ExpandAbsDir(cfgMgr.DetermineWorkDir(cfg.Common.RootDir, rootDir, rootDirChange))
 	cfg.Common.WorkDir = workdir
 	cfg.Common.Debug = debug
+	cfg.Common.DryRun = dryRun
+	cfg.Common.Offline = offline || bundleFile != ""
+	cfg.Common.Bundle = bundleFile
 	rootPath := utils.ExpandAbsDir(cfgMgr.DetermineRootDir(cfg.Common.RootDir, rootDir, rootDirChange))
 	cfgMgr.SetDefaults(cfg, rootPath)
 	adapter.LoadPluginsFromAdapter(ui, cfg)
-	loader.LoadPluginsFromLoader(rootDir, ui)
+	loader.LoadPluginsFromLoader(ui, cfg)
 	cmds := plugin.Commands(ui, cfg, workdir)
 	for _, p := range cmds {
 		rootCmd.AddCommand(p)
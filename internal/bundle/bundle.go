@@ -0,0 +1,146 @@
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// manifestFile 离线包中的清单文件名
+const manifestFile = "manifest.json"
+
+// File 离线包中的一个产物
+type File struct {
+	Name   string `json:"name"`             // 逻辑名称，如 "docker"、"ohmyzsh-plugin/zsh-autosuggestions"
+	Path   string `json:"path"`             // 包内相对路径
+	SHA256 string `json:"sha256,omitempty"` // 目录没有校验和
+	Size   int64  `json:"size,omitempty"`
+}
+
+// Manifest 离线包清单：目标架构、解析好的版本以及包含的文件
+type Manifest struct {
+	Arch     string            `json:"arch"`
+	Created  time.Time         `json:"created"`
+	Versions map[string]string `json:"versions"`
+	Files    []File            `json:"files"`
+}
+
+// Bundle 已解压的离线包
+type Bundle struct {
+	Manifest
+	Dir string
+}
+
+type bundleKey struct{}
+
+// WithBundle 将离线包放入 context，manager 的各个步骤从中读取产物
+func WithBundle(ctx context.Context, b *Bundle) context.Context {
+	return context.WithValue(ctx, bundleKey{}, b)
+}
+
+// FromContext 返回 context 中的离线包，未使用离线包时返回 nil
+func FromContext(ctx context.Context) *Bundle {
+	b, _ := ctx.Value(bundleKey{}).(*Bundle)
+	return b
+}
+
+// Open 解压离线包到 cacheDir/bundles 下并读取清单，file 为空时返回 nil。
+// 离线包未变化时复用上次解压的内容；干跑模式下同样会解压，以便读取清单
+func Open(console ui.UI, file, cacheDir string) (*Bundle, error) {
+	if file == "" {
+		return nil, nil
+	}
+	file = utils.ExpandAbsDir(file)
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+
+	name := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(filepath.Base(file), ".tgz"), ".gz"), ".tar")
+	dir := filepath.Join(utils.ExpandAbsDir(cacheDir), "bundles", name)
+	stamp := fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano())
+	stampFile := filepath.Join(dir, ".source")
+
+	if data, err := os.ReadFile(stampFile); err != nil || string(data) != stamp {
+		console.Info("Extracting bundle %s", file)
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, err
+		}
+		// 解压时会拒绝越出 dir 的条目与链接，失败时不保留不完整的内容
+		if err := utils.ExtractTarGzWithProgress(context.Background(), console, file, dir, 0); err != nil {
+			_ = os.RemoveAll(dir)
+			return nil, fmt.Errorf("failed to extract bundle: %w", err)
+		}
+		b, err := load(dir)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.verify(); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(stampFile, []byte(stamp), 0o600); err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+	return load(dir)
+}
+
+func load(dir string) (*Bundle, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("bundle has no %s: %w", manifestFile, err)
+	}
+	b := &Bundle{Dir: dir}
+	if err := json.Unmarshal(data, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+	// 清单中的路径同样来自外部，必须指向解压目录之内
+	for _, f := range b.Files {
+		if filepath.IsAbs(f.Path) || !utils.WithinDir(dir, filepath.Join(dir, f.Path)) {
+			return nil, fmt.Errorf("bundle manifest has an invalid path for %s: %s", f.Name, f.Path)
+		}
+	}
+	return b, nil
+}
+
+// verify 校验包内每个文件的 SHA256
+func (b *Bundle) verify() error {
+	for _, f := range b.Files {
+		if f.SHA256 == "" {
+			continue
+		}
+		if err := utils.VerifySHA256(filepath.Join(b.Dir, f.Path), f.SHA256); err != nil {
+			return fmt.Errorf("bundle is corrupt: %w", err)
+		}
+	}
+	return nil
+}
+
+// Version 返回清单中记录的版本
+func (b *Bundle) Version(name string) string {
+	return b.Versions[name]
+}
+
+// Path 返回产物解压后的绝对路径
+func (b *Bundle) Path(name string) (string, error) {
+	for _, f := range b.Files {
+		if f.Name == name {
+			return filepath.Join(b.Dir, f.Path), nil
+		}
+	}
+	return "", fmt.Errorf("bundle does not contain %s", name)
+}
+
+// Has 判断离线包中是否包含指定产物
+func (b *Bundle) Has(name string) bool {
+	_, err := b.Path(name)
+	return err == nil
+}
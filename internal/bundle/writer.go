@@ -0,0 +1,140 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Writer 生成离线包：文件依次写入 tar.gz，清单在 Close 时写入
+type Writer struct {
+	f        *os.File
+	gzw      *gzip.Writer
+	tw       *tar.Writer
+	manifest Manifest
+}
+
+// Create 创建离线包文件，arch 为目标架构（utils.ArchType 的取值）
+func Create(path, arch string) (*Writer, error) {
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	gzw := gzip.NewWriter(f)
+	return &Writer{
+		f:   f,
+		gzw: gzw,
+		tw:  tar.NewWriter(gzw),
+		manifest: Manifest{
+			Arch:     arch,
+			Created:  time.Now(),
+			Versions: map[string]string{},
+		},
+	}, nil
+}
+
+// Arch 返回目标架构
+func (w *Writer) Arch() string {
+	return w.manifest.Arch
+}
+
+// SetVersion 记录解析好的版本，离线安装时直接使用
+func (w *Writer) SetVersion(name, version string) {
+	w.manifest.Versions[name] = version
+}
+
+// AddFile 将 src 写入包内的 rel 路径
+func (w *Writer) AddFile(name, src, rel string) error {
+	sum, size, err := w.writeFile(src, rel)
+	if err != nil {
+		return err
+	}
+	w.manifest.Files = append(w.manifest.Files, File{Name: name, Path: filepath.ToSlash(rel), SHA256: sum, Size: size})
+	return nil
+}
+
+// AddDir 将目录 src 下的所有文件写入包内的 rel 目录
+func (w *Writer) AddDir(name, src, rel string) error {
+	var total int64
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		_, size, err := w.writeFile(path, filepath.Join(rel, relPath))
+		total += size
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	w.manifest.Files = append(w.manifest.Files, File{Name: name, Path: filepath.ToSlash(rel), Size: total})
+	return nil
+}
+
+// writeFile 写入单个文件并返回其 SHA256 和大小
+func (w *Writer) writeFile(src, rel string) (string, int64, error) {
+	f, err := os.Open(filepath.Clean(src))
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return "", 0, err
+	}
+	hdr.Name = filepath.ToSlash(rel)
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return "", 0, err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w.tw, h), f); err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), info.Size(), nil
+}
+
+// Manifest 返回当前清单
+func (w *Writer) Manifest() Manifest {
+	return w.manifest
+}
+
+// Close 写入清单并关闭文件
+func (w *Writer) Close() error {
+	data, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := w.tw.WriteHeader(&tar.Header{Name: manifestFile, Mode: 0o600, Size: int64(len(data)), ModTime: time.Now()}); err != nil {
+		return err
+	}
+	if _, err := w.tw.Write(data); err != nil {
+		return err
+	}
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	if err := w.gzw.Close(); err != nil {
+		return err
+	}
+	return w.f.Close()
+}
+
+// Abort 放弃生成并删除未完成的文件
+func (w *Writer) Abort() {
+	_ = w.f.Close()
+	_ = os.Remove(w.f.Name())
+}
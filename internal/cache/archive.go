@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// Export 将索引及其记录的文件打包为 tar.gz，用于在离线机器上导入
+func (c *Cache) Export(ctx context.Context, tarGzPath string) error {
+	if d := utils.DryRunFromContext(ctx); d != nil {
+		d.Record("export", tarGzPath, fmt.Sprintf("%d entries from %s", len(c.Entries), c.Dir))
+		return nil
+	}
+
+	f, err := os.Create(filepath.Clean(tarGzPath))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gzw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gzw)
+
+	index, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: indexFile, Mode: 0o600, Size: int64(len(index))}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(index); err != nil {
+		return err
+	}
+	for _, e := range c.Entries {
+		if err := addFile(tw, c.abs(e), e.Path); err != nil {
+			return fmt.Errorf("failed to export %s: %w", e.Path, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gzw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func addFile(tw *tar.Writer, path, name string) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	hdr.Name = filepath.ToSlash(name)
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// Import 导入 Export 生成的 tar.gz：校验每个文件的 SHA256 后放入缓存目录并合并索引，返回导入的条目数
+func (c *Cache) Import(ctx context.Context, console ui.UI, tarGzPath string) (int, error) {
+	if d := utils.DryRunFromContext(ctx); d != nil {
+		d.Record("import", tarGzPath, "-> "+c.Dir)
+		return 0, nil
+	}
+
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		return 0, err
+	}
+	tmpDir, err := os.MkdirTemp(c.Dir, ".import-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := utils.ExtractTarGzWithProgress(ctx, console, tarGzPath, tmpDir, 0); err != nil {
+		return 0, err
+	}
+	imported, err := Open(tmpDir)
+	if err != nil {
+		return 0, err
+	}
+	if len(imported.Entries) == 0 {
+		return 0, fmt.Errorf("%s does not contain a cache index", tarGzPath)
+	}
+
+	count := 0
+	for _, e := range imported.Entries {
+		// 归档中的文件已由解压时的路径检查限制在 tmpDir 内，这里只需保证索引记录的路径同样不越界
+		src, dst := imported.abs(e), c.abs(e)
+		if filepath.IsAbs(e.Path) || !utils.WithinDir(tmpDir, src) || !utils.WithinDir(c.Dir, dst) {
+			console.Warning("Skipping %s: invalid path", e.Path)
+			continue
+		}
+		// 没有固定的 checksum 时用导出时记录的摘要检测传输损坏
+		sum := e.SHA256
+		if sum == "" {
+			sum = e.Observed
+		}
+		if sum != "" {
+			if err := utils.VerifySHA256(src, sum); err != nil {
+				console.Warning("Skipping %s: %v", e.Path, err)
+				continue
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+			return count, err
+		}
+		if err := os.Rename(src, dst); err != nil {
+			return count, err
+		}
+		entry := *e
+		if err := c.Record(entry); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, c.Save(ctx)
+}
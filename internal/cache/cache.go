@@ -0,0 +1,231 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// indexFile 缓存目录下的索引文件名
+const indexFile = "index.json"
+
+// internalDirs 缓存目录下不属于下载产物的子目录：GitHub API 响应缓存与离线包解包目录
+var internalDirs = []string{"github", "bundles"}
+
+// Entry 缓存中的一个下载产物
+type Entry struct {
+	Name     string    `json:"name"`
+	Version  string    `json:"version,omitempty"`
+	Arch     string    `json:"arch,omitempty"`
+	URL      string    `json:"url,omitempty"`
+	SHA256   string    `json:"sha256,omitempty"`          // 下载时校验过的 checksum，未固定时为空
+	Observed string    `json:"observed_sha256,omitempty"` // 未固定 checksum 时记录的文件摘要，只用于导出导入时检测损坏
+	Size     int64     `json:"size"`
+	Path     string    `json:"path"` // 相对缓存目录的路径
+	LastUsed time.Time `json:"last_used"`
+}
+
+// Cache 缓存目录及其索引
+type Cache struct {
+	Dir     string   `json:"-"`
+	Entries []*Entry `json:"entries"`
+}
+
+// Open 读取缓存目录的索引，索引不存在时返回空缓存
+func Open(dir string) (*Cache, error) {
+	c := &Cache{Dir: utils.ExpandAbsDir(dir)}
+	if err := utils.ReadJSONFile(filepath.Join(c.Dir, indexFile), c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Track 记录一次下载或使用，供 manager 在下载完成后调用；干跑模式下不记录
+func Track(ctx context.Context, dir string, entry Entry) error {
+	if utils.DryRunFromContext(ctx) != nil {
+		return nil
+	}
+	c, err := Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := c.Record(entry); err != nil {
+		return err
+	}
+	return c.Save(ctx)
+}
+
+// Save 写回索引
+func (c *Cache) Save(ctx context.Context) error {
+	sort.Slice(c.Entries, func(i, j int) bool { return c.Entries[i].Path < c.Entries[j].Path })
+	return utils.WriteJSONFile(ctx, filepath.Join(c.Dir, indexFile), c)
+}
+
+// Record 新增或更新条目，Path 可以是绝对路径；大小从文件读取。
+// 未提供 SHA256 时只计算 Observed，不把未经校验的摘要当作 checksum，verify 会提示没有记录 checksum
+func (c *Cache) Record(entry Entry) error {
+	rel, err := c.relPath(entry.Path)
+	if err != nil {
+		return err
+	}
+	entry.Path = rel
+
+	info, err := os.Stat(c.abs(&entry))
+	if err != nil {
+		return err
+	}
+	existing := c.Find(rel)
+	switch {
+	case entry.SHA256 != "":
+		entry.Observed = ""
+	case entry.Observed != "":
+	case existing != nil && existing.Size == info.Size() && existing.Observed != "":
+		// 文件未变化时沿用已记录的值，避免每次使用都重新计算
+		entry.Observed = existing.Observed
+	default:
+		if entry.Observed, err = utils.FileSHA256(c.abs(&entry)); err != nil {
+			return err
+		}
+	}
+	entry.Size = info.Size()
+	entry.LastUsed = time.Now()
+
+	if existing != nil {
+		*existing = entry
+	} else {
+		c.Entries = append(c.Entries, &entry)
+	}
+	return nil
+}
+
+// Find 按相对路径查找条目
+func (c *Cache) Find(rel string) *Entry {
+	for _, e := range c.Entries {
+		if e.Path == rel {
+			return e
+		}
+	}
+	return nil
+}
+
+// Remove 删除条目对应的文件并从索引中移除
+func (c *Cache) Remove(ctx context.Context, entry *Entry) error {
+	if err := utils.RemoveAll(ctx, c.abs(entry)); err != nil {
+		return err
+	}
+	// 同时清理未完成的下载
+	if err := utils.RemoveAll(ctx, c.abs(entry)+".part"); err != nil {
+		return err
+	}
+	for i, e := range c.Entries {
+		if e == entry {
+			c.Entries = append(c.Entries[:i], c.Entries[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Prune 删除超过 olderThan 未使用的条目，并且每个产物只保留最近使用的 keepLast 个版本；参数为 0 表示不按该条件清理
+func (c *Cache) Prune(ctx context.Context, olderThan time.Duration, keepLast int) ([]*Entry, error) {
+	byName := make(map[string][]*Entry)
+	for _, e := range c.Entries {
+		byName[e.Name] = append(byName[e.Name], e)
+	}
+
+	var removed []*Entry
+	now := time.Now()
+	for _, entries := range byName {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].LastUsed.After(entries[j].LastUsed) })
+		for i, e := range entries {
+			expired := olderThan > 0 && now.Sub(e.LastUsed) > olderThan
+			surplus := keepLast > 0 && i >= keepLast
+			if expired || surplus {
+				removed = append(removed, e)
+			}
+		}
+	}
+	for _, e := range removed {
+		if err := c.Remove(ctx, e); err != nil {
+			return nil, err
+		}
+	}
+	return removed, nil
+}
+
+// Verify 重新计算每个条目的 SHA256，返回有问题的条目及原因
+func (c *Cache) Verify() map[*Entry]error {
+	problems := make(map[*Entry]error)
+	for _, e := range c.Entries {
+		if !utils.PathExists(c.abs(e)) {
+			problems[e] = fmt.Errorf("file missing")
+			continue
+		}
+		if e.SHA256 == "" {
+			continue
+		}
+		if err := utils.VerifySHA256(c.abs(e), e.SHA256); err != nil {
+			problems[e] = err
+		}
+	}
+	return problems
+}
+
+// Untracked 返回缓存目录中未被索引记录的下载产物（相对路径），
+// 不包括 GitHub API 缓存、离线包解包目录以及未完成的 .part 下载
+func (c *Cache) Untracked() ([]string, error) {
+	var files []string
+	err := filepath.Walk(c.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, _ := filepath.Rel(c.Dir, path)
+		if info.IsDir() {
+			if slices.Contains(internalDirs, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel == indexFile || strings.HasSuffix(rel, ".part") || c.Find(rel) != nil {
+			return nil
+		}
+		files = append(files, rel)
+		return nil
+	})
+	return files, err
+}
+
+// TotalSize 返回索引中所有条目的大小之和
+func (c *Cache) TotalSize() int64 {
+	var total int64
+	for _, e := range c.Entries {
+		total += e.Size
+	}
+	return total
+}
+
+func (c *Cache) abs(e *Entry) string {
+	return filepath.Join(c.Dir, e.Path)
+}
+
+// relPath 将路径转换为相对缓存目录的路径，拒绝缓存目录之外的路径
+func (c *Cache) relPath(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(c.Dir, path)
+	}
+	rel, err := filepath.Rel(c.Dir, filepath.Clean(path))
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is not inside cache dir %s", path, c.Dir)
+	}
+	return rel, nil
+}
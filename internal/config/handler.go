@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"time"
 
 	yaml "gopkg.in/yaml.v3"
 
@@ -151,6 +152,19 @@ func (m *Manager) LoadConfig(
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
+	cfg.declared = map[string]bool{
+		"ansible":   cfg.Ansible != nil,
+		"python":    cfg.Python != nil,
+		"go":        cfg.Go != nil,
+		"oh-my-zsh": cfg.OhMyzsh != nil,
+		"docker":    cfg.Docker != nil,
+
+		"containerd": cfg.Containerd != nil,
+		"podman":     cfg.Podman != nil,
+		"k3s":        cfg.K3s != nil,
+
+		"tools": cfg.Tools != nil,
+	}
 	return &cfg, nil
 }
 
@@ -177,6 +191,14 @@ func (m *Manager) SetDefaults(cfg *GlobalConfig, rootDir string) {
 
 	// 设置 Docker 默认值
 	m.setDockerDefaults(cfg)
+
+	// 设置 containerd、podman、k3s 默认值
+	m.setContainerdDefaults(cfg)
+	m.setPodmanDefaults(cfg)
+	m.setK3sDefaults(cfg)
+
+	// 设置 tools 默认值
+	m.setToolsDefaults(cfg)
 }
 
 func (m *Manager) setCommonDefaults(cfg *GlobalConfig, rootDir string) {
@@ -193,6 +215,18 @@ func (m *Manager) setCommonDefaults(cfg *GlobalConfig, rootDir string) {
 	if cfg.Common.CacheDir == "" {
 		cfg.Common.CacheDir = filepath.Join(cfg.Common.RootDir, "cache")
 	}
+
+	// 下载超时与重试
+	if cfg.Common.ConnectTimeout == 0 {
+		cfg.Common.ConnectTimeout = 10 * time.Second
+	}
+	if cfg.Common.ReadTimeout == 0 {
+		cfg.Common.ReadTimeout = 30 * time.Second
+	}
+	if cfg.Common.Retries == nil {
+		retries := 3
+		cfg.Common.Retries = &retries
+	}
 }
 
 func (m *Manager) setAnsibleDefaults(cfg *GlobalConfig) {
@@ -272,6 +306,35 @@ func (m *Manager) setDockerDefaults(cfg *GlobalConfig) {
 	if cfg.Docker.Version == "" {
 		cfg.Docker.Version = "26.1.0"
 	}
+	if len(cfg.Docker.Mirrors) == 0 {
+		cfg.Docker.Mirrors = []string{
+			"https://mirrors.aliyun.com/docker-ce/linux/static/stable",
+			"https://download.docker.com/linux/static/stable",
+		}
+	}
+	if cfg.Docker.ComposeVersion == "" {
+		cfg.Docker.ComposeVersion = "v2.29.7"
+	}
+	if cfg.Docker.BuildxVersion == "" {
+		cfg.Docker.BuildxVersion = "v0.17.1"
+	}
+	if cfg.Docker.Plugins == nil {
+		cfg.Docker.Plugins = []string{"compose", "buildx"}
+	}
+	// 未声明 daemon 时沿用开启 IPv6 的默认配置，rootless 模式不支持 IPv6 网段
+	if cfg.Docker.Daemon == nil && !cfg.Docker.Rootless {
+		cfg.Docker.Daemon = map[string]interface{}{
+			"ipv6":          true,
+			"fixed-cidr-v6": "fd00::/80",
+		}
+	}
+	if cfg.Docker.ServiceManager == "" {
+		cfg.Docker.ServiceManager = "auto"
+	}
+	if cfg.Docker.ManageGroup == nil {
+		manageGroup := true
+		cfg.Docker.ManageGroup = &manageGroup
+	}
 	if cfg.Docker.InstallDir == "" {
 		cfg.Docker.InstallDir = filepath.Join(cfg.Common.RootDir, "docker")
 	}
@@ -280,6 +343,77 @@ func (m *Manager) setDockerDefaults(cfg *GlobalConfig) {
 	}
 }
 
+func (m *Manager) setContainerdDefaults(cfg *GlobalConfig) {
+	if cfg.Containerd == nil {
+		cfg.Containerd = &ContainerdConfig{}
+	}
+	if cfg.Containerd.InstallDir == "" {
+		cfg.Containerd.InstallDir = filepath.Join(cfg.Common.RootDir, "containerd")
+	}
+	if cfg.Containerd.Version == "" {
+		cfg.Containerd.Version = "1.7.22"
+	}
+	if cfg.Containerd.RuncVersion == "" {
+		cfg.Containerd.RuncVersion = "1.1.14"
+	}
+	if cfg.Containerd.NerdctlVersion == "" {
+		cfg.Containerd.NerdctlVersion = "1.7.7"
+	}
+	if cfg.Containerd.CNIVersion == "" {
+		cfg.Containerd.CNIVersion = "1.5.1"
+	}
+	if cfg.Containerd.ServiceManager == "" {
+		cfg.Containerd.ServiceManager = "auto"
+	}
+}
+
+func (m *Manager) setPodmanDefaults(cfg *GlobalConfig) {
+	if cfg.Podman == nil {
+		cfg.Podman = &PodmanConfig{}
+	}
+	if cfg.Podman.InstallDir == "" {
+		cfg.Podman.InstallDir = filepath.Join(cfg.Common.RootDir, "podman")
+	}
+	if cfg.Podman.Version == "" {
+		cfg.Podman.Version = "5.2.3"
+	}
+	if cfg.Podman.ServiceManager == "" {
+		cfg.Podman.ServiceManager = "auto"
+	}
+}
+
+func (m *Manager) setK3sDefaults(cfg *GlobalConfig) {
+	if cfg.K3s == nil {
+		cfg.K3s = &K3sConfig{}
+	}
+	if cfg.K3s.InstallDir == "" {
+		cfg.K3s.InstallDir = filepath.Join(cfg.Common.RootDir, "k3s")
+	}
+	if cfg.K3s.Version == "" {
+		cfg.K3s.Version = "v1.30.5+k3s1"
+	}
+	if len(cfg.K3s.Args) == 0 {
+		cfg.K3s.Args = []string{"server", "--write-kubeconfig-mode=644"}
+	}
+	if cfg.K3s.ServiceManager == "" {
+		cfg.K3s.ServiceManager = "auto"
+	}
+}
+
+func (m *Manager) setToolsDefaults(cfg *GlobalConfig) {
+	for _, tool := range cfg.Tools {
+		if tool.Version == "" {
+			tool.Version = "latest"
+		}
+		if tool.Tag == "" {
+			tool.Tag = "v{{.Version}}"
+		}
+		if len(tool.Binaries) == 0 && tool.Name != "" {
+			tool.Binaries = []string{tool.Name}
+		}
+	}
+}
+
 func (m *Manager) UpdateRootDir(cfg *GlobalConfig, rootDir string) {
 	rootDir = utils.ExpandAbsDir(rootDir)
 
@@ -298,4 +432,7 @@ func (m *Manager) UpdateRootDir(cfg *GlobalConfig, rootDir string) {
 
 	cfg.OhMyzsh.InstallDir = filepath.Join(cfg.Common.RootDir, "oh-my-zsh")
 	cfg.Docker.InstallDir = filepath.Join(cfg.Common.RootDir, "docker")
+	cfg.Containerd.InstallDir = filepath.Join(cfg.Common.RootDir, "containerd")
+	cfg.Podman.InstallDir = filepath.Join(cfg.Common.RootDir, "podman")
+	cfg.K3s.InstallDir = filepath.Join(cfg.Common.RootDir, "k3s")
 }
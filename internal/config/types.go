@@ -1,5 +1,11 @@
 package config
 
+import (
+	"time"
+
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
 type AnsibleConfig struct {
 	BaseDir    string `yaml:"base-dir"`
 	PythonDir  string `yaml:"python-dir"`
@@ -7,7 +13,8 @@ type AnsibleConfig struct {
 }
 
 type LangConfig struct {
-	BaseDir  string   `yaml:"base-dir"`
+	BaseDir string `yaml:"base-dir"`
+	// Versions、Global 可以写完整版本，也可以写 "~3.12"、">=3.11 <3.13" 等约束，约束取满足条件的最高版本
 	Versions []string `yaml:"versions"`
 	Global   string   `yaml:"global"`
 }
@@ -31,18 +38,113 @@ type OhMyzshConfig struct {
 
 type CommonConfig struct {
 	Debug       bool   `yaml:"debug"`
+	DryRun      bool   `yaml:"-"` // 由 --dry-run 设置，不写入配置文件
+	Offline     bool   `yaml:"-"` // 由 --offline 设置，禁止访问网络
+	Bundle      string `yaml:"-"` // 由 --bundle 设置，从离线包安装（隐含 --offline）
 	RootDir     string `yaml:"root-dir"`
 	WorkDir     string `yaml:"work-dir"`
 	CacheDir    string `yaml:"cache-dir"`
 	GithubProxy string `yaml:"github-proxy"`
 	HttpProxy   string `yaml:"http-proxy"`
+	// GithubToken 访问 GitHub API 的 token，未配置时使用 GITHUB_TOKEN 环境变量
+	GithubToken string `yaml:"github-token"`
+	// Checksums 固定下载文件的 SHA256，key 为下载地址的路径后缀，如 "x86_64/docker-26.1.0.tgz"
+	Checksums map[string]string `yaml:"checksums"`
+	// 下载的连接超时、读取超时（如 "10s"）及每个地址的重试次数，未配置重试次数时默认 3，设为 0 表示不重试
+	ConnectTimeout time.Duration `yaml:"connect-timeout"`
+	ReadTimeout    time.Duration `yaml:"read-timeout"`
+	Retries        *int          `yaml:"retries"`
+}
+
+// DownloadOptions 根据公共配置生成下载参数
+func (c *CommonConfig) DownloadOptions(checksum string) utils.DownloadOptions {
+	opts := utils.DownloadOptions{
+		HttpProxy:      c.HttpProxy,
+		Checksum:       checksum,
+		ConnectTimeout: c.ConnectTimeout,
+		ReadTimeout:    c.ReadTimeout,
+	}
+	if c.Retries != nil {
+		opts.Retries = *c.Retries
+	}
+	return opts
 }
 
 type DockerConfig struct {
-	InstallDir      string   `yaml:"install-dir"`
+	InstallDir string `yaml:"install-dir"`
+	// Version 完整版本，或 "~26.1"、">=25 <27"、"latest-stable" 等约束，约束在发布列表中取满足条件的最高正式版本
 	Version         string   `yaml:"version" default:"26.1.0"`
 	HttpProxy       string   `yaml:"http-proxy"`
 	RegistryMirrors []string `yaml:"registry-mirrors"`
+	// Mirrors 静态安装包的下载地址，按顺序尝试，前一个失败时换下一个
+	Mirrors []string `yaml:"mirrors"`
+	// ComposeVersion、BuildxVersion 插件版本，设为 latest 或约束时每次安装都查询发布列表
+	ComposeVersion string `yaml:"compose-version" default:"v2.29.7"`
+	BuildxVersion  string `yaml:"buildx-version" default:"v0.17.1"`
+	// Plugins 要安装的 CLI 插件（compose、buildx），默认全部安装
+	Plugins []string `yaml:"plugins"`
+	// Daemon 写入 daemon.json 的键（如 data-root、log-driver、exec-opts、insecure-registries），
+	// 与文件中已有内容深度合并，未声明的键保持不变
+	Daemon map[string]interface{} `yaml:"daemon"`
+	// Rootless 以当前用户运行 rootless Docker，不需要 sudo，服务与配置写入 ~/.config 下
+	Rootless bool `yaml:"rootless"`
+	// ServiceManager 注册服务的方式：auto（自动探测）、systemd、openrc、sysv 或 supervised（直接托管进程）
+	ServiceManager string `yaml:"service-manager" default:"auto"`
+	// ManageGroup 创建 docker 组并将当前用户加入，使其无需 sudo 即可访问 docker.sock，设为 false 时不修改用户组
+	ManageGroup *bool `yaml:"manage-group" default:"true"`
+}
+
+// ContainerdConfig 独立安装的 containerd，附带 runc、nerdctl 与 CNI 插件
+type ContainerdConfig struct {
+	InstallDir     string `yaml:"install-dir"`
+	Version        string `yaml:"version" default:"1.7.22"`
+	RuncVersion    string `yaml:"runc-version" default:"1.1.14"`
+	NerdctlVersion string `yaml:"nerdctl-version" default:"1.7.7"`
+	CNIVersion     string `yaml:"cni-version" default:"1.5.1"`
+	// ServiceManager 注册服务的方式，取值同 docker.service-manager
+	ServiceManager string `yaml:"service-manager" default:"auto"`
+}
+
+// PodmanConfig podman 静态构建（mgoltzsche/podman-static）
+type PodmanConfig struct {
+	InstallDir string `yaml:"install-dir"`
+	Version    string `yaml:"version" default:"5.2.3"`
+	// Service 注册 podman system service，在 /run/podman/podman.sock 提供兼容 Docker 的 API
+	Service        bool   `yaml:"service"`
+	ServiceManager string `yaml:"service-manager" default:"auto"`
+}
+
+// K3sConfig 单节点 k3s
+type K3sConfig struct {
+	InstallDir string `yaml:"install-dir"`
+	Version    string `yaml:"version" default:"v1.30.5+k3s1"`
+	// Args k3s 的启动参数，默认以 server 运行并允许普通用户读取 kubeconfig
+	Args           []string `yaml:"args"`
+	ServiceManager string   `yaml:"service-manager" default:"auto"`
+}
+
+// ToolConfig 以 GitHub release 发布的单个命令行工具，如 ripgrep、fzf、helm
+type ToolConfig struct {
+	Name string `yaml:"name"`
+	// Repo GitHub 仓库，如 "junegunn/fzf"
+	Repo string `yaml:"repo"`
+	// Version 不带 v 前缀的版本，设为 latest 或 "~1.5" 等约束时每次安装都查询发布列表
+	Version string `yaml:"version" default:"latest"`
+	// Prerelease 查询 latest 时包含预发布版本
+	Prerelease bool `yaml:"prerelease"`
+	// Tag release 的 tag 模板，默认 "v{{.Version}}"
+	Tag string `yaml:"tag"`
+	// Asset 发布文件名模板，可用 {{.Version}}、{{.Arch}}（amd64/arm64）、{{.Machine}}（x86_64/aarch64）、{{.OS}}
+	Asset string `yaml:"asset"`
+	// Archive 发布文件类型：tar.gz、zip 或 binary（单个可执行文件），默认按 Asset 的扩展名判断
+	Archive string `yaml:"archive"`
+	// Strip 解压时去掉的目录层数
+	Strip int `yaml:"strip"`
+	// Binaries 要放入 PATH 的可执行文件，可以是文件名或包内相对路径，默认与 Name 相同
+	Binaries []string `yaml:"binaries"`
+	// Checksum 校验来源："sha256:<hash>" 固定值，或 release 中校验文件的文件名模板（如 "checksums.txt"、"{{.Asset}}.sha256"），
+	// 为空时使用 common.checksums，仍未找到时使用 release 中记录的 digest
+	Checksum string `yaml:"checksum"`
 }
 
 type GlobalConfig struct {
@@ -52,4 +154,19 @@ type GlobalConfig struct {
 	Go      *LangConfig    `yaml:"go"`
 	OhMyzsh *OhMyzshConfig `yaml:"oh-my-zsh"`
 	Docker  *DockerConfig  `yaml:"docker"`
+
+	Containerd *ContainerdConfig `yaml:"containerd"`
+	Podman     *PodmanConfig     `yaml:"podman"`
+	K3s        *K3sConfig        `yaml:"k3s"`
+
+	// Tools 由 tool 命令安装的 GitHub release 工具
+	Tools []*ToolConfig `yaml:"tools"`
+
+	// declared 记录配置文件中实际声明的配置段（SetDefaults 之前）
+	declared map[string]bool
+}
+
+// Declared 判断配置文件中是否声明了指定配置段，section 为 yaml 键名，如 "docker"
+func (c *GlobalConfig) Declared(section string) bool {
+	return c.declared[section]
 }
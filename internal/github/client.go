@@ -0,0 +1,411 @@
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+	"github.com/bookandmusic/dev-tools/internal/version"
+)
+
+const (
+	apiBase = "https://api.github.com"
+	// maxPages ListReleases 最多读取的页数（每页 100 个 release）
+	maxPages = 10
+	// defaultMaxWait 触发限流时最多等待的时间，超过则直接报错并提示重试时间
+	defaultMaxWait = time.Minute
+)
+
+// Client GitHub REST API 客户端。
+// 配置了 token 时以认证方式访问（5000 次/小时），响应按 ETag 缓存在 CacheDir/github 下，
+// 未变化的响应返回 304，不消耗匿名请求的限额
+type Client struct {
+	UI          ui.UI
+	BaseURL     string // API 地址，默认 https://api.github.com
+	Token       string
+	GithubProxy string
+	HttpProxy   string
+	CacheDir    string        // 为空时不缓存
+	MaxWait     time.Duration // 触发限流时最多等待的时间
+
+	http *http.Client
+}
+
+// Release GitHub release 及其发布文件
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []Asset   `json:"assets"`
+}
+
+// Asset release 中的一个发布文件
+type Asset struct {
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	Digest             string `json:"digest"` // 如 "sha256:<hash>"，较早的 release 没有
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// SHA256 返回 digest 中的 SHA256，没有时返回空字符串
+func (a *Asset) SHA256() string {
+	if hash, ok := strings.CutPrefix(a.Digest, "sha256:"); ok {
+		return hash
+	}
+	return ""
+}
+
+// Asset 按文件名查找发布文件
+func (r *Release) Asset(name string) (*Asset, error) {
+	names := make([]string, 0, len(r.Assets))
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i], nil
+		}
+		names = append(names, r.Assets[i].Name)
+	}
+	return nil, fmt.Errorf("release %s has no asset %s (available: %s)", r.TagName, name, strings.Join(names, ", "))
+}
+
+// RateLimitError 请求限额已用完
+type RateLimitError struct {
+	Reset         time.Time
+	Authenticated bool
+}
+
+func (e *RateLimitError) Error() string {
+	msg := fmt.Sprintf("GitHub API rate limit exceeded, retry after %s (in %s)",
+		e.Reset.Local().Format("15:04:05"), time.Until(e.Reset).Round(time.Second))
+	if !e.Authenticated {
+		msg += "; set common.github-token or GITHUB_TOKEN to raise the limit"
+	}
+	return msg
+}
+
+// ErrNotFound 仓库、release 或 tag 不存在
+var ErrNotFound = errors.New("not found")
+
+// New 根据公共配置创建客户端，token 取 common.github-token，未配置时取 GITHUB_TOKEN 环境变量
+func New(console ui.UI, global *config.CommonConfig) *Client {
+	token := global.GithubToken
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+	cacheDir := ""
+	if global.CacheDir != "" {
+		cacheDir = filepath.Join(utils.ExpandAbsDir(global.CacheDir), "github")
+	}
+	return &Client{
+		UI:          console,
+		BaseURL:     apiBase,
+		Token:       token,
+		GithubProxy: global.GithubProxy,
+		HttpProxy:   global.HttpProxy,
+		CacheDir:    cacheDir,
+		MaxWait:     defaultMaxWait,
+	}
+}
+
+// LatestRelease 返回最新的 release，prerelease 为 true 时也考虑预发布版本，草稿始终跳过
+func (c *Client) LatestRelease(ctx context.Context, repo string, prerelease bool) (*Release, error) {
+	if err := checkRepo(repo); err != nil {
+		return nil, err
+	}
+	if !prerelease {
+		var r Release
+		if err := c.get(ctx, fmt.Sprintf("/repos/%s/releases/latest", repo), &r); err != nil {
+			return nil, fmt.Errorf("failed to get latest release of %s: %w", repo, err)
+		}
+		return &r, nil
+	}
+	var releases []Release
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/releases?per_page=30", repo), &releases); err != nil {
+		return nil, fmt.Errorf("failed to list releases of %s: %w", repo, err)
+	}
+	for i := range releases {
+		if !releases[i].Draft {
+			return &releases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s has no releases: %w", repo, ErrNotFound)
+}
+
+// LatestTag 返回最新正式 release 的 tag
+func (c *Client) LatestTag(ctx context.Context, repo string) (string, error) {
+	r, err := c.LatestRelease(ctx, repo, false)
+	if err != nil {
+		return "", err
+	}
+	if r.TagName == "" {
+		return "", fmt.Errorf("tag_name not found in release info")
+	}
+	return r.TagName, nil
+}
+
+// ResolveTag 返回满足版本约束的最新 release tag：latest 直接查询最新 release，
+// 其余约束（如 "~26.1"、">=25 <27"）在全部 release 的 tag 中查找，prerelease 为 false 时跳过预发布版本
+func (c *Client) ResolveTag(ctx context.Context, repo, constraint string, prerelease bool) (string, error) {
+	if version.IsLatest(constraint) {
+		r, err := c.LatestRelease(ctx, repo, prerelease)
+		if err != nil {
+			return "", err
+		}
+		return r.TagName, nil
+	}
+	releases, err := c.ListReleases(ctx, repo, prerelease)
+	if err != nil {
+		return "", err
+	}
+	tags := make([]string, 0, len(releases))
+	for _, r := range releases {
+		tags = append(tags, r.TagName)
+	}
+	tag, err := version.Resolve(constraint, tags, prerelease)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", repo, err)
+	}
+	return tag, nil
+}
+
+// ReleaseByTag 返回指定 tag 的 release
+func (c *Client) ReleaseByTag(ctx context.Context, repo, tag string) (*Release, error) {
+	if err := checkRepo(repo); err != nil {
+		return nil, err
+	}
+	var r Release
+	if err := c.get(ctx, fmt.Sprintf("/repos/%s/releases/tags/%s", repo, url.PathEscape(tag)), &r); err != nil {
+		return nil, fmt.Errorf("failed to get release %s of %s: %w", tag, repo, err)
+	}
+	return &r, nil
+}
+
+// ListReleases 返回全部 release（按发布时间倒序），prerelease 为 false 时过滤预发布版本，草稿始终跳过
+func (c *Client) ListReleases(ctx context.Context, repo string, prerelease bool) ([]Release, error) {
+	if err := checkRepo(repo); err != nil {
+		return nil, err
+	}
+	var all []Release
+	for page := 1; page <= maxPages; page++ {
+		var releases []Release
+		if err := c.get(ctx, fmt.Sprintf("/repos/%s/releases?per_page=100&page=%d", repo, page), &releases); err != nil {
+			return nil, fmt.Errorf("failed to list releases of %s: %w", repo, err)
+		}
+		for _, r := range releases {
+			if r.Draft || (r.Prerelease && !prerelease) {
+				continue
+			}
+			all = append(all, r)
+		}
+		if len(releases) < 100 {
+			break
+		}
+	}
+	return all, nil
+}
+
+// checkRepo 校验仓库名格式
+func checkRepo(repo string) error {
+	if repo == "" || !strings.Contains(repo, "/") {
+		return fmt.Errorf("invalid repo: %s, expected format 'owner/repo'", repo)
+	}
+	return nil
+}
+
+// cachedResponse 磁盘上缓存的响应
+type cachedResponse struct {
+	ETag string          `json:"etag"`
+	Body json.RawMessage `json:"body"`
+}
+
+// cachePath 返回 API 路径对应的缓存文件，token 不同不影响响应内容，因此不参与计算
+func (c *Client) cachePath(apiPath string) string {
+	sum := sha256.Sum256([]byte(apiPath))
+	return filepath.Join(c.CacheDir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// readCache 读取缓存的响应，不存在或损坏时返回 nil
+func (c *Client) readCache(apiPath string) *cachedResponse {
+	if c.CacheDir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(c.cachePath(apiPath))
+	if err != nil {
+		return nil
+	}
+	var cached cachedResponse
+	if json.Unmarshal(data, &cached) != nil || len(cached.Body) == 0 {
+		return nil
+	}
+	return &cached
+}
+
+// writeCache 保存带 ETag 的响应；缓存只是优化，写入失败不影响请求结果
+func (c *Client) writeCache(apiPath, etag string, body []byte) {
+	if c.CacheDir == "" || etag == "" {
+		return
+	}
+	data, err := json.Marshal(cachedResponse{ETag: etag, Body: body})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.CacheDir, 0o755); err != nil {
+		return
+	}
+	_ = os.WriteFile(c.cachePath(apiPath), data, 0o644)
+}
+
+// get 请求 API 并将 JSON 解析到 v。离线时使用缓存的响应；触发限流时在 MaxWait 内等待重置后重试
+func (c *Client) get(ctx context.Context, apiPath string, v any) error {
+	cached := c.readCache(apiPath)
+	if utils.IsOffline(ctx) {
+		if cached == nil {
+			return utils.ErrOffline
+		}
+		return json.Unmarshal(cached.Body, v)
+	}
+
+	for {
+		body, err := c.do(ctx, apiPath, cached)
+		var limited *RateLimitError
+		if errors.As(err, &limited) {
+			wait := time.Until(limited.Reset)
+			if wait > c.MaxWait {
+				// 宁可使用可能过期的缓存，也不要让安装失败
+				if cached != nil {
+					c.UI.Warning("%v, using cached response", err)
+					return json.Unmarshal(cached.Body, v)
+				}
+				return err
+			}
+			c.UI.Warning("GitHub API rate limit exceeded, waiting %s", wait.Round(time.Second))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(body, v)
+	}
+}
+
+// do 发送一次请求，返回响应体；304 时返回缓存内容
+func (c *Client) do(ctx context.Context, apiPath string, cached *cachedResponse) ([]byte, error) {
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	apiURL := strings.TrimSuffix(c.BaseURL, "/") + apiPath
+	// 使用 github-proxy 时请求会经过第三方，带 token 时不走代理以免泄露
+	if c.HttpProxy == "" && c.Token == "" {
+		apiURL = utils.ProxyURL(c.UI, c.GithubProxy, apiURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "dev-tools") // GitHub API 要求 UA
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if cached != nil {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	c.warnLowLimit(resp.Header)
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return cached.Body, nil
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		c.writeCache(apiPath, resp.Header.Get("ETag"), body)
+		return body, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNotFound
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		if reset, ok := rateLimitReset(resp.Header); ok {
+			return nil, &RateLimitError{Reset: reset, Authenticated: c.Token != ""}
+		}
+	case resp.StatusCode == http.StatusUnauthorized:
+		return nil, fmt.Errorf("HTTP 401: invalid GitHub token")
+	}
+	return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+}
+
+// rateLimitReset 从响应头判断是否触发限流并返回可重试的时间：
+// 主限额用完时为 X-RateLimit-Reset，二级限流时为 Retry-After
+func rateLimitReset(h http.Header) (time.Time, bool) {
+	if after, err := strconv.Atoi(h.Get("Retry-After")); err == nil {
+		return time.Now().Add(time.Duration(after) * time.Second), true
+	}
+	if h.Get("X-RateLimit-Remaining") != "0" {
+		return time.Time{}, false
+	}
+	reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(reset, 0), true
+}
+
+// warnLowLimit 剩余请求数较少时提醒用户
+func (c *Client) warnLowLimit(h http.Header) {
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil || remaining == 0 || remaining > 5 {
+		return
+	}
+	msg := fmt.Sprintf("Only %d GitHub API request(s) left", remaining)
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		msg += fmt.Sprintf(", limit resets at %s", time.Unix(reset, 0).Local().Format("15:04:05"))
+	}
+	if c.Token == "" {
+		msg += "; set common.github-token or GITHUB_TOKEN to raise the limit"
+	}
+	c.UI.Warning("%s", msg)
+}
+
+// client 返回 HTTP 客户端，配置了 http-proxy 时通过代理访问
+func (c *Client) client() (*http.Client, error) {
+	if c.http != nil {
+		return c.http, nil
+	}
+	c.http = &http.Client{Timeout: 30 * time.Second}
+	if c.HttpProxy != "" {
+		proxyURL, err := url.Parse(c.HttpProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HTTP proxy URL: %w", err)
+		}
+		c.http.Transport = &http.Transport{Proxy: http.ProxyURL(proxyURL)}
+	}
+	return c.http, nil
+}
@@ -0,0 +1,76 @@
+package lock
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// lockFile RootDir 下记录实际安装版本的文件名
+const lockFile = "dtl.lock"
+
+// Record 一个软件实际安装的版本及其组件版本
+type Record struct {
+	Version     string            `json:"version"`
+	Arch        string            `json:"arch,omitempty"`
+	Components  map[string]string `json:"components,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"` // manager 自行记录的安装状态，卸载时据此清理
+	InstalledAt time.Time         `json:"installed_at"`
+}
+
+// Lock RootDir/dtl.lock 的内容，键为 manager 名称
+type Lock struct {
+	path     string
+	Packages map[string]Record `json:"packages"`
+}
+
+// Open 读取 rootDir 下的 lock 文件，文件不存在时返回空记录
+func Open(rootDir string) (*Lock, error) {
+	l := &Lock{
+		path:     filepath.Join(utils.ExpandAbsDir(rootDir), lockFile),
+		Packages: map[string]Record{},
+	}
+	if err := utils.ReadJSONFile(l.path, l); err != nil {
+		return nil, err
+	}
+	if l.Packages == nil {
+		l.Packages = map[string]Record{}
+	}
+	return l, nil
+}
+
+// Get 返回指定软件的记录
+func (l *Lock) Get(name string) (Record, bool) {
+	r, ok := l.Packages[name]
+	return r, ok
+}
+
+// Set 更新指定软件的记录，InstalledAt 为空时使用当前时间
+func (l *Lock) Set(name string, r Record) {
+	if r.InstalledAt.IsZero() {
+		r.InstalledAt = time.Now()
+	}
+	l.Packages[name] = r
+}
+
+// Delete 删除指定软件的记录
+func (l *Lock) Delete(name string) {
+	delete(l.Packages, name)
+}
+
+// Save 写回 lock 文件，干跑模式下只记录计划写入的内容
+func (l *Lock) Save(ctx context.Context) error {
+	return utils.WriteJSONFile(ctx, l.path, l)
+}
+
+// Update 打开 lock 文件，修改后立即保存
+func Update(ctx context.Context, rootDir string, fn func(l *Lock)) error {
+	l, err := Open(rootDir)
+	if err != nil {
+		return err
+	}
+	fn(l)
+	return l.Save(ctx)
+}
@@ -0,0 +1,219 @@
+package language
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/bookandmusic/dev-tools/internal/cache"
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+	"github.com/bookandmusic/dev-tools/internal/version"
+)
+
+const (
+	goDownloadURL = "https://go.dev/dl"
+	goVersionURL  = "https://go.dev/VERSION?m=text"
+)
+
+type GoManager struct{}
+
+// Install 下载 Go SDK 并解压到 BaseDir/<version>，多个版本可以并存
+// version 支持 "1.23.2"、"go1.23.2"、"latest" 以及 "~1.22"、">=1.21 <1.23" 等约束
+func (g GoManager) Install(ctx context.Context, version string) error {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return err
+	}
+	ui := params.UI
+	cfg := params.Cfg
+	global := params.Global
+
+	goVersion := normalizeGoVersion(version)
+	if goVersion == "" {
+		return fmt.Errorf("invalid go version: %q", version)
+	}
+	if needsResolve(goVersion) {
+		ui.Info("Resolving Go version %s...", goVersion)
+		if utils.IsOffline(ctx) {
+			return fmt.Errorf("a full Go version is required in offline mode: %w", utils.ErrOffline)
+		}
+		if goVersion, err = resolveGoVersion(ctx, global, goVersion); err != nil {
+			ui.Error("Failed to resolve Go version %s", version)
+			return err
+		}
+	}
+
+	installDir := filepath.Join(cfg.BaseDir, goVersion)
+	if utils.PathExists(filepath.Join(installDir, "bin", "go")) {
+		ui.Info("Go %s already installed in %s", goVersion, installDir)
+	} else {
+		if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+			return errors.New("unsupported operating system: " + runtime.GOOS)
+		}
+		if utils.DetectArch() == utils.ArchUnknown {
+			return errors.New("unsupported architecture: " + runtime.GOARCH)
+		}
+
+		tarFile := fmt.Sprintf("go%s.%s-%s.tar.gz", goVersion, runtime.GOOS, runtime.GOARCH)
+		cacheFile := filepath.Join(global.CacheDir, "go", tarFile)
+		if err := downloadToCache(ctx, ui, global, cache.Entry{
+			Name: "go", Version: goVersion, Arch: runtime.GOARCH, URL: fmt.Sprintf("%s/%s", goDownloadURL, tarFile), Path: cacheFile,
+		}, false); err != nil {
+			return err
+		}
+
+		ui.Info("Extracting %s to %s", tarFile, installDir)
+		if err := utils.ExtractTarGzWithProgress(ctx, ui, cacheFile, installDir, 1); err != nil {
+			_ = os.RemoveAll(installDir)
+			return fmt.Errorf("failed to extract %s: %w", tarFile, err)
+		}
+		ui.Success("Go %s installed", goVersion)
+	}
+
+	if Matches(normalizeGoVersion(cfg.Global), goVersion) {
+		return g.Active(ctx, goVersion)
+	}
+	return nil
+}
+
+// Uninstall 删除指定版本，若该版本处于激活状态则同时清理 GOROOT 与 PATH
+func (g GoManager) Uninstall(ctx context.Context, version string) error {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return err
+	}
+	ui := params.UI
+	cfg := params.Cfg
+	global := params.Global
+
+	goVersion := normalizeGoVersion(version)
+	installDir := filepath.Join(cfg.BaseDir, goVersion)
+	if goVersion == "" || !utils.PathExists(installDir) {
+		return fmt.Errorf("go %s is not installed", version)
+	}
+
+	active, err := activeVersion(ctx, global, cfg.BaseDir)
+	if err != nil {
+		return err
+	}
+	if active == goVersion {
+		ui.Info("Removing active Go %s from environment", goVersion)
+		if err := removeFromPath(ctx, global, cfg.BaseDir); err != nil {
+			return err
+		}
+		if err := utils.UpdateEnvFile(ctx, envFilePath(global), map[string]string{"GOROOT": ""}, "remove"); err != nil {
+			return err
+		}
+	}
+
+	ui.Info("Removing %s", installDir)
+	if err := utils.RemoveAll(ctx, installDir); err != nil {
+		return err
+	}
+	ui.Success("Go %s uninstalled", goVersion)
+	return nil
+}
+
+// List 列出已安装与配置中声明的版本
+func (g GoManager) List(ctx context.Context) error {
+	return listVersions(ctx, "Go", g.Normalize)
+}
+
+// Current 返回当前激活的版本
+func (g GoManager) Current(ctx context.Context) (string, error) {
+	return currentVersion(ctx)
+}
+
+// Normalize 去掉 "go"/"v" 前缀，"1.23.2" 与 "go1.23.2" 视为同一版本
+func (g GoManager) Normalize(version string) string {
+	return normalizeGoVersion(version)
+}
+
+// Active 改写 .env 中的 GOROOT 与 PATH，切换到指定版本；约束会匹配已安装的最高版本
+func (g GoManager) Active(ctx context.Context, version string) error {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return err
+	}
+	ui := params.UI
+	cfg := params.Cfg
+	global := params.Global
+
+	installed, err := InstalledVersions(cfg.BaseDir)
+	if err != nil {
+		return err
+	}
+	goVersion := installedTarget(g, installed, version)
+	// 干跑模式下待安装的版本尚未真正落盘
+	if !slices.Contains(installed, goVersion) && utils.DryRunFromContext(ctx) == nil {
+		return fmt.Errorf("go %s is not installed, run `install %s` first", version, version)
+	}
+
+	goRoot := filepath.Join(cfg.BaseDir, goVersion)
+	if err := switchPath(ctx, global, cfg.BaseDir, filepath.Join(goRoot, "bin")); err != nil {
+		ui.Error("Failed to update environment")
+		return err
+	}
+	if err := utils.UpdateEnvFile(ctx, envFilePath(global), map[string]string{"GOROOT": goRoot}, "add"); err != nil {
+		ui.Error("Failed to update environment")
+		return err
+	}
+	ui.Success("Go %s is now active, reload your shell to apply", goVersion)
+	return nil
+}
+
+// normalizeGoVersion 去掉 "go"/"v" 前缀，"1.23.2" 与 "go1.23.2" 视为同一版本
+func normalizeGoVersion(version string) string {
+	version = strings.TrimSpace(version)
+	version = strings.TrimPrefix(version, "go")
+	return strings.TrimPrefix(version, "v")
+}
+
+// latestGoVersion 查询 go.dev 发布的最新稳定版本
+func latestGoVersion(ctx context.Context, global *config.CommonConfig) (string, error) {
+	content, err := utils.FetchText(ctx, goVersionURL, global.DownloadOptions(""))
+	if err != nil {
+		return "", fmt.Errorf("failed to get latest go version: %w", err)
+	}
+	// 返回内容第一行形如 "go1.23.2"
+	first, _, _ := strings.Cut(content, "\n")
+	return normalizeGoVersion(strings.TrimSpace(first)), nil
+}
+
+// goRelease go.dev/dl 发布列表中的一项
+type goRelease struct {
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+}
+
+// resolveGoVersion 在 go.dev 发布列表中查找满足约束的最高稳定版本
+func resolveGoVersion(ctx context.Context, global *config.CommonConfig, constraint string) (string, error) {
+	if version.IsLatest(constraint) {
+		return latestGoVersion(ctx, global)
+	}
+	content, err := utils.FetchText(ctx, goDownloadURL+"/?mode=json&include=all", global.DownloadOptions(""))
+	if err != nil {
+		return "", err
+	}
+	var releases []goRelease
+	if err := json.Unmarshal([]byte(content), &releases); err != nil {
+		return "", fmt.Errorf("failed to parse go release list: %w", err)
+	}
+	candidates := make([]string, 0, len(releases))
+	for _, r := range releases {
+		candidates = append(candidates, normalizeGoVersion(r.Version))
+	}
+	return version.Resolve(constraint, candidates, false)
+}
+
+func init() {
+	Register("go", GoManager{})
+}
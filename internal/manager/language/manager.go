@@ -1,10 +1,118 @@
 package language
 
-import "github.com/bookandmusic/dev-tools/internal/ui"
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/bookandmusic/dev-tools/internal/version"
+)
 
 type Manager interface {
-	Install(version string, ui ui.UI)
-	Uninstall(version string, ui ui.UI)
-	List(ui ui.UI)
-	Active(version string, ui ui.UI)
+	Install(ctx context.Context, version string) error
+	Uninstall(ctx context.Context, version string) error
+	List(ctx context.Context) error
+	Active(ctx context.Context, version string) error
+	// Current 返回 .env 中当前激活的版本，未激活时返回空字符串
+	Current(ctx context.Context) (string, error)
+	// Normalize 将用户输入的版本转换为安装目录名，用于比较版本
+	Normalize(version string) string
+}
+
+var (
+	registry = make(map[string]Manager)
+	mu       sync.RWMutex
+)
+
+func Register(name string, m Manager) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[name] = m
+}
+
+func GetManager(name string) (Manager, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	m, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("language manager %s not found", name)
+	}
+	return m, nil
+}
+
+// Names 返回已注册的语言名称（已排序）
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MatchVersion 在配置的 versions 中查找与 version 对应的条目
+// versions 为空时不做限制，直接返回 version；否则返回配置中的原始写法（如 "3.12.7+20241016"）。
+// 配置中写的是约束（如 "~3.12"）时，满足约束的版本也视为匹配，此时返回 version 本身
+func MatchVersion(m Manager, versions []string, v string) (string, error) {
+	if len(versions) == 0 {
+		return v, nil
+	}
+	target := m.Normalize(v)
+	for _, configured := range versions {
+		if m.Normalize(configured) == target {
+			return configured, nil
+		}
+	}
+	for _, configured := range versions {
+		if Matches(m.Normalize(configured), target) {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("version %s is not declared in config versions %v", v, versions)
+}
+
+// Matches 判断规范化后的版本 have 是否满足配置的写法 want：相同，或 want 为约束且 have 满足该约束
+func Matches(want, have string) bool {
+	if want == have {
+		return true
+	}
+	return version.IsConstraint(want) && version.Match(want, have)
+}
+
+// Satisfied 判断已安装的版本中是否有满足配置写法 want 的版本
+func Satisfied(m Manager, installed []string, want string) bool {
+	return PickInstalled(m, installed, want) != ""
+}
+
+// PickInstalled 返回已安装版本中满足 want 的版本，约束匹配多个版本时取最高的，没有时返回空字符串
+func PickInstalled(m Manager, installed []string, want string) string {
+	target := m.Normalize(want)
+	if slices.Contains(installed, target) {
+		return target
+	}
+	if !version.IsConstraint(target) {
+		return ""
+	}
+	picked, err := version.Resolve(target, installed, true)
+	if err != nil {
+		return ""
+	}
+	return picked
+}
+
+// installedTarget 返回 want 对应的已安装版本：约束取满足条件的最高已安装版本，找不到时原样返回规范化后的写法
+func installedTarget(m Manager, installed []string, want string) string {
+	if picked := PickInstalled(m, installed, want); picked != "" {
+		return picked
+	}
+	return m.Normalize(want)
+}
+
+// needsResolve 版本写法是否需要联网查询发布列表：latest 或约束
+func needsResolve(v string) bool {
+	return version.IsLatest(v) || version.IsConstraint(v)
 }
@@ -0,0 +1,12 @@
+package language
+
+import (
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+)
+
+type BaseParams struct {
+	UI     ui.UI                `ctx:"ui"`
+	Cfg    *config.LangConfig   `ctx:"cfg"`
+	Global *config.CommonConfig `ctx:"global"`
+}
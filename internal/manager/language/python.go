@@ -1,23 +1,261 @@
 package language
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bookandmusic/dev-tools/internal/cache"
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/github"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft"
 	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+	"github.com/bookandmusic/dev-tools/internal/version"
 )
 
+// pythonBuildRepo 提供独立可移植 Python 构建的 GitHub 仓库
+const pythonBuildRepo = "astral-sh/python-build-standalone"
+
 type PythonManager struct{}
 
-func (p PythonManager) Install(version string, ui ui.UI) {
-	ui.Info("Installing Python...")
+// Install 安装指定版本的 Python
+// version 可以是 "3.12.7"，也可以带上构建发布标签 "3.12.7+20241016"；未指定发布标签时查找提供该版本的发布。
+// "latest"、"~3.12" 等约束在该发布提供的版本中选择满足条件的最高版本
+func (p PythonManager) Install(ctx context.Context, version string) error {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return err
+	}
+	ui := params.UI
+	cfg := params.Cfg
+	global := params.Global
+
+	pyVersion, release := splitPythonVersion(version)
+	if pyVersion == "" {
+		return fmt.Errorf("invalid python version: %q", version)
+	}
+	if needsResolve(pyVersion) {
+		if utils.IsOffline(ctx) {
+			return fmt.Errorf("a full Python version is required in offline mode: %w", utils.ErrOffline)
+		}
+		ui.Info("Resolving Python version %s...", pyVersion)
+		triple, err := pythonTriple()
+		if err != nil {
+			return err
+		}
+		if pyVersion, release, err = resolvePython(ctx, github.New(ui, global), pyVersion, release, triple); err != nil {
+			ui.Error("Failed to resolve Python version %s", version)
+			return err
+		}
+	}
+
+	installDir := filepath.Join(cfg.BaseDir, pyVersion)
+	if utils.PathExists(filepath.Join(installDir, "bin")) {
+		ui.Info("Python %s already installed in %s", pyVersion, installDir)
+	} else {
+		triple, err := pythonTriple()
+		if err != nil {
+			return err
+		}
+		if release == "" {
+			// 每次发布只包含各 minor 的最新 patch，固定的旧版本需要找到实际提供它的发布
+			if release, err = findPythonRelease(ctx, ui, global, pyVersion, triple); err != nil {
+				return err
+			}
+		}
+
+		asset := fmt.Sprintf("cpython-%s+%s-%s-install_only.tar.gz", pyVersion, release, triple)
+		cacheFile := filepath.Join(global.CacheDir, "python", asset)
+		downloadURL := fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", pythonBuildRepo, release, asset)
+		if err := downloadToCache(ctx, ui, global, cache.Entry{
+			Name: "python", Version: pyVersion, Arch: triple, URL: downloadURL, Path: cacheFile,
+		}, true); err != nil {
+			return err
+		}
+
+		ui.Info("Extracting %s to %s", asset, installDir)
+		if err := utils.ExtractTarGzWithProgress(ctx, ui, cacheFile, installDir, 1); err != nil {
+			_ = os.RemoveAll(installDir)
+			return fmt.Errorf("failed to extract %s: %w", asset, err)
+		}
+		ui.Success("Python %s installed", pyVersion)
+	}
+
+	if Matches(p.Normalize(cfg.Global), pyVersion) {
+		return p.Active(ctx, pyVersion)
+	}
+	return nil
+}
+
+// Uninstall 删除指定版本，若该版本处于激活状态则同时清理 PATH
+func (p PythonManager) Uninstall(ctx context.Context, version string) error {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return err
+	}
+	ui := params.UI
+	cfg := params.Cfg
+	global := params.Global
+
+	pyVersion := p.Normalize(version)
+	installDir := filepath.Join(cfg.BaseDir, pyVersion)
+	if pyVersion == "" || !utils.PathExists(installDir) {
+		return fmt.Errorf("python %s is not installed", version)
+	}
+
+	active, err := activeVersion(ctx, global, cfg.BaseDir)
+	if err != nil {
+		return err
+	}
+	if active == pyVersion {
+		ui.Info("Removing active Python %s from PATH", pyVersion)
+		if err := removeFromPath(ctx, global, cfg.BaseDir); err != nil {
+			return err
+		}
+	}
+
+	ui.Info("Removing %s", installDir)
+	if err := utils.RemoveAll(ctx, installDir); err != nil {
+		return err
+	}
+	ui.Success("Python %s uninstalled", pyVersion)
+	return nil
+}
+
+// List 列出已安装与配置中声明的版本
+func (p PythonManager) List(ctx context.Context) error {
+	return listVersions(ctx, "Python", p.Normalize)
 }
 
-func (p PythonManager) Uninstall(version string, ui ui.UI) {
-	ui.Info("Uninstalling Python...")
+// Current 返回当前激活的版本
+func (p PythonManager) Current(ctx context.Context) (string, error) {
+	return currentVersion(ctx)
+}
+
+// Normalize 去掉构建发布标签，"3.12.7+20241016" 规范化为 "3.12.7"
+func (p PythonManager) Normalize(version string) string {
+	pyVersion, _ := splitPythonVersion(version)
+	return pyVersion
+}
+
+// Active 将指定版本的 bin 目录写入 .env 的 PATH；约束会匹配已安装的最高版本
+func (p PythonManager) Active(ctx context.Context, version string) error {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return err
+	}
+	ui := params.UI
+	cfg := params.Cfg
+
+	installed, err := InstalledVersions(cfg.BaseDir)
+	if err != nil {
+		return err
+	}
+	pyVersion := installedTarget(p, installed, version)
+	// 干跑模式下待安装的版本尚未真正落盘
+	if !slices.Contains(installed, pyVersion) && utils.DryRunFromContext(ctx) == nil {
+		return fmt.Errorf("python %s is not installed, run `install %s` first", version, version)
+	}
+
+	binDir := filepath.Join(cfg.BaseDir, pyVersion, "bin")
+	if err := switchPath(ctx, params.Global, cfg.BaseDir, binDir); err != nil {
+		ui.Error("Failed to update environment")
+		return err
+	}
+	ui.Success("Python %s is now active, reload your shell to apply", pyVersion)
+	return nil
+}
+
+// splitPythonVersion 拆分 "3.12.7+20241016" 为 Python 版本与构建发布标签
+func splitPythonVersion(version string) (string, string) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	pyVersion, release, _ := strings.Cut(version, "+")
+	return pyVersion, release
+}
+
+// findPythonRelease 查找提供指定 Python 版本安装包的 python-build-standalone 发布标签。
+// 离线时只在缓存中查找，联网时从新到旧搜索发布列表；都找不到时要求写明 <version>+<release>
+func findPythonRelease(ctx context.Context, console ui.UI, global *config.CommonConfig, pyVersion, triple string) (string, error) {
+	prefix := "cpython-" + pyVersion + "+"
+	suffix := "-" + triple + "-install_only.tar.gz"
+	if utils.IsOffline(ctx) {
+		matches, _ := filepath.Glob(filepath.Join(global.CacheDir, "python", prefix+"*"+suffix))
+		if len(matches) == 0 {
+			return "", fmt.Errorf("a release tag (e.g. %s+20241016) is required in offline mode: %w", pyVersion, utils.ErrOffline)
+		}
+		sort.Strings(matches)
+		name := filepath.Base(matches[len(matches)-1])
+		return strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix), nil
+	}
+
+	console.Info("Searching python-build-standalone releases for Python %s...", pyVersion)
+	releases, err := github.New(console, global).ListReleases(ctx, pythonBuildRepo, false)
+	if err != nil {
+		console.Error("Failed to list python-build-standalone releases")
+		return "", err
+	}
+	for _, r := range releases {
+		if _, err := r.Asset(prefix + r.TagName + suffix); err == nil {
+			return r.TagName, nil
+		}
+	}
+	return "", fmt.Errorf("no python-build-standalone release provides Python %s for %s, specify it as %s+<release>", pyVersion, triple, pyVersion)
+}
+
+// resolvePython 在 python-build-standalone 的某次发布（未指定 release 时为最新发布）中
+// 查找当前平台满足约束的最高 Python 版本，返回版本与发布标签
+func resolvePython(ctx context.Context, client *github.Client, constraint, release, triple string) (string, string, error) {
+	var (
+		rel *github.Release
+		err error
+	)
+	if release == "" {
+		rel, err = client.LatestRelease(ctx, pythonBuildRepo, false)
+	} else {
+		rel, err = client.ReleaseByTag(ctx, pythonBuildRepo, release)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	// 资产名形如 cpython-3.12.7+20241016-x86_64-unknown-linux-gnu-install_only.tar.gz
+	suffix := "+" + rel.TagName + "-" + triple + "-install_only.tar.gz"
+	var candidates []string
+	for _, asset := range rel.Assets {
+		if name, ok := strings.CutPrefix(asset.Name, "cpython-"); ok && strings.HasSuffix(name, suffix) {
+			candidates = append(candidates, strings.TrimSuffix(name, suffix))
+		}
+	}
+	picked, err := version.Resolve(constraint, candidates, false)
+	if err != nil {
+		return "", "", fmt.Errorf("python-build-standalone %s: %w", rel.TagName, err)
+	}
+	return picked, rel.TagName, nil
 }
 
-func (p PythonManager) List(ui ui.UI) {
-	ui.Info("Listing Python...")
+// pythonTriple 返回当前平台对应的构建三元组
+func pythonTriple() (string, error) {
+	arch := utils.DetectArch()
+	if arch == utils.ArchUnknown {
+		return "", errors.New("unsupported architecture: " + runtime.GOARCH)
+	}
+	switch runtime.GOOS {
+	case "linux":
+		return fmt.Sprintf("%s-unknown-linux-gnu", arch), nil
+	case "darwin":
+		return fmt.Sprintf("%s-apple-darwin", arch), nil
+	default:
+		return "", errors.New("unsupported operating system: " + runtime.GOOS)
+	}
 }
 
-func (p PythonManager) Active(ui ui.UI) {
-	ui.Info("Activating Python...")
+func init() {
+	Register("python", PythonManager{})
 }
@@ -0,0 +1,176 @@
+package language
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bookandmusic/dev-tools/internal/cache"
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// ========== 多版本共用的辅助方法 ==========
+
+// envFilePath 返回工具根目录下的 .env 路径
+func envFilePath(global *config.CommonConfig) string {
+	return filepath.Join(global.RootDir, ".env")
+}
+
+// InstalledVersions 列出 baseDir 下已安装的版本（每个版本一个子目录）
+func InstalledVersions(baseDir string) ([]string, error) {
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var versions []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			versions = append(versions, entry.Name())
+		}
+	}
+	return versions, nil
+}
+
+// activeVersion 根据 .env 中的 PATH 找出 baseDir 下当前激活的版本
+func activeVersion(ctx context.Context, global *config.CommonConfig, baseDir string) (string, error) {
+	envMap, err := utils.ReadEnvFile(ctx, envFilePath(global))
+	if err != nil {
+		return "", err
+	}
+	for _, p := range utils.SplitPathList(envMap["PATH"]) {
+		rel, err := filepath.Rel(baseDir, p)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if version := strings.Split(rel, string(filepath.Separator))[0]; version != "." {
+			return version, nil
+		}
+	}
+	return "", nil
+}
+
+// switchPath 从 .env 的 PATH 中移除 baseDir 下其他版本的 bin 目录，并加入 binDir
+func switchPath(ctx context.Context, global *config.CommonConfig, baseDir, binDir string) error {
+	if err := removeFromPath(ctx, global, baseDir); err != nil {
+		return err
+	}
+	if binDir == "" {
+		return nil
+	}
+	return utils.UpdateEnvFile(ctx, envFilePath(global), map[string]string{"PATH": binDir}, "add")
+}
+
+// removeFromPath 从 .env 的 PATH 中移除所有位于 baseDir 下的目录
+func removeFromPath(ctx context.Context, global *config.CommonConfig, baseDir string) error {
+	envFile := envFilePath(global)
+	envMap, err := utils.ReadEnvFile(ctx, envFile)
+	if err != nil {
+		return err
+	}
+	for _, p := range utils.SplitPathList(envMap["PATH"]) {
+		rel, err := filepath.Rel(baseDir, p)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if err := utils.UpdateEnvFile(ctx, envFile, map[string]string{"PATH": p}, "remove"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listVersions 输出已安装与配置中声明的版本，版本号经 normalize 规范化后比较
+func listVersions(ctx context.Context, name string, normalize func(string) string) error {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return err
+	}
+	installed, err := InstalledVersions(params.Cfg.BaseDir)
+	if err != nil {
+		return err
+	}
+	active, err := activeVersion(ctx, params.Global, params.Cfg.BaseDir)
+	if err != nil {
+		return err
+	}
+	configured := make([]string, 0, len(params.Cfg.Versions))
+	for _, v := range params.Cfg.Versions {
+		configured = append(configured, normalize(v))
+	}
+	printVersions(params.UI, name, configured, normalize(params.Cfg.Global), installed, active)
+	return nil
+}
+
+// currentVersion 返回 .env 中 BaseDir 下处于激活状态的版本
+func currentVersion(ctx context.Context) (string, error) {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return "", err
+	}
+	return activeVersion(ctx, params.Global, params.Cfg.BaseDir)
+}
+
+// printVersions 输出已安装版本与配置中要求的版本，configured/global 需已规范化为目录名
+func printVersions(console ui.UI, name string, configured []string, global string, installed []string, active string) {
+	all := slices.Clone(installed)
+	for _, v := range configured {
+		if !slices.Contains(all, v) {
+			all = append(all, v)
+		}
+	}
+	slices.Sort(all)
+
+	if len(all) == 0 {
+		console.Info("No %s versions installed or configured", name)
+		return
+	}
+
+	console.Println("%-3s %-20s %-10s %-10s", "", "VERSION", "INSTALLED", "CONFIGURED")
+	for _, v := range all {
+		mark := ""
+		if v == active {
+			mark = "*"
+		}
+		console.Println("%-3s %-20s %-10s %-10s", mark, v,
+			yesNo(slices.Contains(installed, v)), yesNo(slices.Contains(configured, v)))
+	}
+	if global != "" && global != active {
+		console.Warning("Configured global version %s is not active, run `use %s`", global, global)
+	}
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// downloadToCache 下载 entry.URL 到 entry.Path，配置了 checksum 时校验缓存与下载结果，完成后记录到缓存索引
+func downloadToCache(ctx context.Context, console ui.UI, global *config.CommonConfig, entry cache.Entry, github bool) error {
+	checksum := utils.PinnedChecksum(global.Checksums, entry.URL)
+	if checksum == "" {
+		console.Warning("No checksum pinned for %s, the download cannot be verified", filepath.Base(entry.Path))
+	}
+	urls := []string{entry.URL}
+	if github {
+		urls = utils.GithubURLs(console, global.GithubProxy, global.HttpProxy, entry.URL)
+	}
+	if err := utils.DownloadToCache(ctx, console, urls, entry.Path, global.DownloadOptions(checksum)); err != nil {
+		return fmt.Errorf("failed to download %s: %w", entry.URL, err)
+	}
+	entry.SHA256 = checksum
+	if err := cache.Track(ctx, global.CacheDir, entry); err != nil {
+		console.Warning("Failed to update cache index: %v", err)
+	}
+	return nil
+}
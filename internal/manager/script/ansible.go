@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"path/filepath"
 
+	"github.com/apenella/go-ansible/v2/pkg/execute"
 	"github.com/apenella/go-ansible/v2/pkg/playbook"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -13,18 +14,21 @@ import (
 )
 
 type AnsibleExecutor struct {
-	ui ui.UI
+	ui   ui.UI
+	opts Options
 }
 
-func NewAnsibleExecutor(ui ui.UI) *AnsibleExecutor {
-	return &AnsibleExecutor{ui: ui}
+func NewAnsibleExecutor(ui ui.UI, opts Options) *AnsibleExecutor {
+	return &AnsibleExecutor{ui: ui, opts: opts}
 }
 
 func (a *AnsibleExecutor) ScriptPath(basePath string, names ...string) string {
 	return filepath.Join(append([]string{basePath}, names...)...) + ".yml"
 }
 
-func (a *AnsibleExecutor) Exec(scriptPath string, cmd *cobra.Command, args []string) error {
+// Exec 执行 playbook，flag 与命名的位置参数均作为 extra vars 传入，
+// DTL_* 环境变量可在 playbook 中通过 lookup('env', 'DTL_ROOT') 读取
+func (a *AnsibleExecutor) Exec(scriptPath string, cmd *cobra.Command, args []string, named []NamedArg) error {
 	ansiblePlaybookOptions := &playbook.AnsiblePlaybookOptions{
 		Connection: "local",
 		Inventory:  "127.0.0.1,",     // 逗号结尾是必须的
@@ -33,19 +37,32 @@ func (a *AnsibleExecutor) Exec(scriptPath string, cmd *cobra.Command, args []str
 
 	// 遍历所有已设置的 flags
 	cmd.Flags().Visit(func(f *pflag.Flag) {
-		ansiblePlaybookOptions.ExtraVars[f.Name] = f.Value.String()
+		ansiblePlaybookOptions.ExtraVars[f.Name] = flagValue(f)
 	})
 
-	// 这里你可以选择是否用 args 传给 ansible，有需要的话可以自行追加
+	// 命名的位置参数，可变参数为列表；未声明 args 时原始位置参数不会传给 playbook
+	for _, arg := range named {
+		ansiblePlaybookOptions.ExtraVars[arg.Name] = arg.Value()
+	}
 
 	// 执行 playbook
-	err := playbook.NewAnsiblePlaybookExecute(scriptPath).
-		WithPlaybookOptions(ansiblePlaybookOptions).
-		Execute(context.TODO())
-
-	return err
+	exec := execute.NewDefaultExecute(
+		execute.WithCmd(&playbook.AnsiblePlaybookCmd{
+			Playbooks:       []string{scriptPath},
+			PlaybookOptions: ansiblePlaybookOptions,
+		}),
+		execute.WithErrorEnrich(playbook.NewAnsiblePlaybookErrorEnrich()),
+		execute.WithEnvVars(a.opts.Env(cmd, named)),
+	)
+	return exec.Execute(context.TODO())
 }
 
 func (a *AnsibleExecutor) NotFoundError(path string) error {
 	return fmt.Errorf("playbook not found: %s", path)
 }
+
+func init() {
+	Register("ansible", func(ui ui.UI, opts Options) PluginExecutor {
+		return NewAnsibleExecutor(ui, opts)
+	})
+}
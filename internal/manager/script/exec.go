@@ -0,0 +1,116 @@
+package script
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// ExecExecutor 直接运行任意可执行文件（Python、Node、编译好的二进制等）
+// 命令对应 <command> 或 <command>.* 文件；配置了 interpreter 时用它运行，
+// 否则可执行文件直接运行，不可执行的文件按首行 shebang 选择解释器
+type ExecExecutor struct {
+	ui          ui.UI
+	opts        Options
+	interpreter []string
+}
+
+func NewExecExecutor(ui ui.UI, opts Options) *ExecExecutor {
+	return &ExecExecutor{ui: ui, opts: opts, interpreter: strings.Fields(opts.Interpreter)}
+}
+
+// ScriptPath 优先使用不带扩展名的 <command>，否则使用第一个 <command>.* 文件
+func (e *ExecExecutor) ScriptPath(basePath string, names ...string) string {
+	path := filepath.Join(append([]string{basePath}, names...)...)
+	if isFile(path) {
+		return path
+	}
+	matches, _ := filepath.Glob(globEscape(path) + ".*")
+	for _, m := range matches {
+		if isFile(m) && filepath.Base(m) != "meta.yml" {
+			return m
+		}
+	}
+	return path
+}
+
+// Exec 执行文件，flag、位置参数与 DTL_* 环境变量的传递方式与 shell 插件相同
+func (e *ExecExecutor) Exec(scriptPath string, cmd *cobra.Command, args []string, named []NamedArg) error {
+	if !isFile(scriptPath) {
+		return e.NotFoundError(scriptPath)
+	}
+	command, err := e.command(scriptPath)
+	if err != nil {
+		return err
+	}
+	cmdArgs := append(command[1:], commandArgs(cmd, args)...)
+	return utils.RunCommand(context.Background(), e.ui, e.opts.Env(cmd, named), command[0], cmdArgs...)
+}
+
+func (e *ExecExecutor) NotFoundError(path string) error {
+	return fmt.Errorf("executable not found: %s", path)
+}
+
+// command 返回运行脚本的命令行（不含参数）
+func (e *ExecExecutor) command(scriptPath string) ([]string, error) {
+	if len(e.interpreter) > 0 {
+		return append(append([]string{}, e.interpreter...), scriptPath), nil
+	}
+	info, err := os.Stat(scriptPath)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().Perm()&0o111 != 0 {
+		return []string{scriptPath}, nil
+	}
+	shebang, err := readShebang(scriptPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(shebang) == 0 {
+		return nil, fmt.Errorf("%s is not executable and has no shebang, set interpreter in meta.yml", scriptPath)
+	}
+	return append(shebang, scriptPath), nil
+}
+
+// readShebang 读取首行的 #! 解释器及其参数，没有 shebang 时返回 nil
+func readShebang(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(line, "#!") {
+		return nil, nil
+	}
+	return strings.Fields(strings.TrimPrefix(line, "#!")), nil
+}
+
+// isFile 判断路径是否为普通文件
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// globEscape 转义路径中的通配符，避免插件目录名被当作匹配模式
+func globEscape(path string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`).Replace(path)
+}
+
+func init() {
+	Register("exec", func(ui ui.UI, opts Options) PluginExecutor {
+		return NewExecExecutor(ui, opts)
+	})
+}
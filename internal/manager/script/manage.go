@@ -1,11 +1,174 @@
 package script
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
 )
 
 type PluginExecutor interface {
 	ScriptPath(basePath string, names ...string) string
-	Exec(scriptPath string, cmd *cobra.Command, args []string) error
+	// Exec 执行脚本，args 为原始位置参数，named 为按 meta.yml args 声明命名后的位置参数
+	Exec(scriptPath string, cmd *cobra.Command, args []string, named []NamedArg) error
 	NotFoundError(path string) error
 }
+
+// Options 创建执行器时使用的 meta.yml 设置与全局配置
+type Options struct {
+	// Interpreter 解释器命令，可带参数，如 "python3 -u"；为空时直接执行脚本
+	Interpreter string
+	// PluginDir 插件所在目录（meta.yml 所在目录）
+	PluginDir string
+	Global    *config.CommonConfig
+}
+
+// Env 返回所有执行器导出给插件的环境变量：
+//
+//	DTL_ROOT        工具根目录
+//	DTL_CACHE_DIR   下载缓存目录
+//	DTL_PLUGIN_DIR  插件自身所在目录
+//	DTL_HTTP_PROXY  配置的 HTTP 代理，未配置时为空
+//	DTL_DEBUG       开启 --debug 时为 1，否则为 0
+//	DTL_ARCH        机器架构，x86_64 或 aarch64
+//	DTL_OPT_<NAME>  meta.yml 中声明的每个选项的值（含默认值），切片以逗号连接
+//	DTL_ARG_<NAME>  命名的位置参数，可变参数以空格连接
+//
+// NAME 为大写的选项或参数名，"-" 与 "." 替换为 "_"
+func (o Options) Env(cmd *cobra.Command, named []NamedArg) map[string]string {
+	env := map[string]string{
+		"DTL_PLUGIN_DIR": o.PluginDir,
+		"DTL_DEBUG":      "0",
+		"DTL_ARCH":       string(utils.DetectArch()),
+	}
+	if o.Global != nil {
+		env["DTL_ROOT"] = o.Global.RootDir
+		env["DTL_CACHE_DIR"] = o.Global.CacheDir
+		env["DTL_HTTP_PROXY"] = o.Global.HttpProxy
+		if o.Global.Debug {
+			env["DTL_DEBUG"] = "1"
+		}
+	}
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Name != "help" {
+			env[EnvName("DTL_OPT_", f.Name)] = flagString(f)
+		}
+	})
+	for _, arg := range named {
+		env[EnvName("DTL_ARG_", arg.Name)] = strings.Join(arg.Values, " ")
+	}
+	return env
+}
+
+// Factory 为某种插件类型（meta.yml 的 type）创建执行器
+type Factory func(ui ui.UI, opts Options) PluginExecutor
+
+var (
+	registry = make(map[string]Factory)
+	mu       sync.RWMutex
+)
+
+// Register 注册插件类型的执行器，同名类型会被覆盖
+func Register(pluginType string, f Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[pluginType] = f
+}
+
+// NewExecutor 创建插件类型对应的执行器
+func NewExecutor(pluginType string, ui ui.UI, opts Options) (PluginExecutor, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	f, ok := registry[pluginType]
+	if !ok {
+		return nil, fmt.Errorf("unknown plugin type %q, supported: %s", pluginType, strings.Join(typesLocked(), ", "))
+	}
+	return f(ui, opts), nil
+}
+
+// Types 返回已注册的插件类型（已排序）
+func Types() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return typesLocked()
+}
+
+func typesLocked() []string {
+	types := make([]string, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// NamedArg 按 meta.yml 中 args 声明命名的位置参数，可变参数包含剩余的全部值
+type NamedArg struct {
+	Name     string
+	Values   []string
+	Variadic bool
+}
+
+// Value 返回传给 ansible 的值：可变参数为列表，其余为字符串
+func (a NamedArg) Value() any {
+	if a.Variadic {
+		return a.Values
+	}
+	return strings.Join(a.Values, " ")
+}
+
+// EnvName 将参数名转换为环境变量名，如 EnvName("DTL_ARG_", "target-host") = "DTL_ARG_TARGET_HOST"
+func EnvName(prefix, name string) string {
+	return prefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// commandArgs 将已设置的 flag 转换为 --name value 形式，并追加原始位置参数
+func commandArgs(cmd *cobra.Command, args []string) []string {
+	var cmdArgs []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		// 对flag名称和值进行基本验证
+		if value := flagString(f); f.Name != "" && value != "" {
+			cmdArgs = append(cmdArgs, "--"+f.Name, value)
+		}
+	})
+	return append(cmdArgs, args...)
+}
+
+// flagString 返回 flag 在命令行上的写法，切片类型用逗号连接
+func flagString(f *pflag.Flag) string {
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		return strings.Join(sv.GetSlice(), ",")
+	}
+	return f.Value.String()
+}
+
+// flagValue 按 flag 类型返回对应的 Go 值，供 ansible extra vars 保留 bool、数字与列表类型
+func flagValue(f *pflag.Flag) any {
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		return sv.GetSlice()
+	}
+	raw := f.Value.String()
+	switch f.Value.Type() {
+	case "bool":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	case "int":
+		if n, err := strconv.Atoi(raw); err == nil {
+			return n
+		}
+	case "float64":
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {
+			return n
+		}
+	}
+	return raw
+}
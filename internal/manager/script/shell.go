@@ -7,19 +7,20 @@ import (
 	"path/filepath"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/pflag"
 
 	"github.com/bookandmusic/dev-tools/internal/ui"
 	"github.com/bookandmusic/dev-tools/internal/utils"
 )
 
 type ShellExecutor struct {
-	ui ui.UI
+	ui   ui.UI
+	opts Options
 }
 
-func NewShellExecutor(ui ui.UI) *ShellExecutor {
+func NewShellExecutor(ui ui.UI, opts Options) *ShellExecutor {
 	return &ShellExecutor{
-		ui: ui,
+		ui:   ui,
+		opts: opts,
 	}
 }
 
@@ -29,30 +30,26 @@ func (s *ShellExecutor) ScriptPath(basePath string, names ...string) string {
 }
 
 // Exec 执行脚本，参数来自 cobra.Command
-func (s *ShellExecutor) Exec(scriptPath string, cmd *cobra.Command, args []string) error {
+// 同时导出 Options.Env 中约定的 DTL_* 环境变量；原始位置参数仍追加在命令行末尾
+func (s *ShellExecutor) Exec(scriptPath string, cmd *cobra.Command, args []string, named []NamedArg) error {
 	// 验证脚本路径是否存在
 	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
 		return s.NotFoundError(scriptPath)
 	}
 
-	// 构建命令参数
-	cmdArgs := []string{scriptPath}
-
-	// 添加flag参数
-	cmd.Flags().Visit(func(f *pflag.Flag) {
-		// 对flag名称和值进行基本验证
-		if f.Name != "" && f.Value.String() != "" {
-			cmdArgs = append(cmdArgs, "--"+f.Name, f.Value.String())
-		}
-	})
-
-	// 添加位置参数
-	cmdArgs = append(cmdArgs, args...)
+	// 构建命令参数：flag 以 --name value 形式追加，随后是位置参数
+	cmdArgs := append([]string{scriptPath}, commandArgs(cmd, args)...)
 	ctx := context.Background()
 	// 使用统一的命令执行方式
-	return utils.RunCommand(ctx, s.ui, nil, "bash", cmdArgs...)
+	return utils.RunCommand(ctx, s.ui, s.opts.Env(cmd, named), "bash", cmdArgs...)
 }
 
 func (s *ShellExecutor) NotFoundError(path string) error {
 	return fmt.Errorf("script not found: %s", path)
 }
+
+func init() {
+	Register("shell", func(ui ui.UI, opts Options) PluginExecutor {
+		return NewShellExecutor(ui, opts)
+	})
+}
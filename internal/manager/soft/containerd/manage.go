@@ -0,0 +1,98 @@
+package containerd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bookandmusic/dev-tools/internal/manager/soft"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft/staticbin"
+	"github.com/bookandmusic/dev-tools/internal/service"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// buildSpec 生成安装描述，resolve 为 true 时解析 latest 版本（需要联网）
+func buildSpec(ctx context.Context, arch utils.ArchType, resolve bool) (*staticbin.Spec, error) {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return nil, err
+	}
+	cfg := params.Cfg
+	global := params.Global
+	versions := map[string]string{
+		"containerd":  cfg.Version,
+		"runc":        cfg.RuncVersion,
+		"nerdctl":     cfg.NerdctlVersion,
+		"cni-plugins": cfg.CNIVersion,
+	}
+	repos := map[string]string{
+		"containerd":  "containerd/containerd",
+		"runc":        "opencontainers/runc",
+		"nerdctl":     "containerd/nerdctl",
+		"cni-plugins": "containernetworking/plugins",
+	}
+	if resolve {
+		for name, version := range versions {
+			v, err := staticbin.ResolveVersion(ctx, params.UI, global, name, repos[name], version)
+			if err != nil {
+				return nil, err
+			}
+			// 发布文件名中的版本不带 v 前缀
+			versions[name] = strings.TrimPrefix(v, "v")
+		}
+	}
+
+	goArch := staticbin.GoArch(arch)
+	release := func(name, file string) []string {
+		return staticbin.GithubRelease(params.UI, global, repos[name], "v"+versions[name], file)
+	}
+	installDir := utils.ExpandAbsDir(cfg.InstallDir)
+	spec := &staticbin.Spec{
+		Name:           "containerd",
+		Title:          "containerd",
+		Version:        versions["containerd"],
+		Arch:           arch,
+		InstallDir:     installDir,
+		ServiceManager: cfg.ServiceManager,
+		VersionArgs:    []string{"containerd", "--version"},
+	}
+	containerdFile := fmt.Sprintf("containerd-%s-linux-%s.tar.gz", versions["containerd"], goArch)
+	nerdctlFile := fmt.Sprintf("nerdctl-%s-linux-%s.tar.gz", versions["nerdctl"], goArch)
+	cniFile := fmt.Sprintf("cni-plugins-linux-%s-v%s.tgz", goArch, versions["cni-plugins"])
+	spec.Artifacts = []staticbin.Artifact{
+		// 包内为 bin/containerd 等文件
+		{Name: "containerd", Version: versions["containerd"], File: containerdFile, URLs: release("containerd", containerdFile), Strip: 1},
+		{Name: "runc", Version: versions["runc"], File: "runc." + goArch, URLs: release("runc", "runc."+goArch), Binary: "runc"},
+		{Name: "nerdctl", Version: versions["nerdctl"], File: nerdctlFile, URLs: release("nerdctl", nerdctlFile)},
+		{Name: "cni-plugins", Version: versions["cni-plugins"], File: cniFile, URLs: release("cni-plugins", cniFile), Dir: "libexec/cni"},
+	}
+
+	cniPath := filepath.Join(installDir, "libexec", "cni")
+	spec.Files = []staticbin.File{
+		{Path: "/etc/nerdctl/nerdctl.toml", Content: fmt.Sprintf("address = \"unix:///run/containerd/containerd.sock\"\ncni_path = %q", cniPath)},
+	}
+	spec.Services = []service.Spec{{
+		Name:        "containerd",
+		Description: "containerd container runtime",
+		Exec:        spec.BinPath("containerd"),
+		Env:         map[string]string{"PATH": filepath.Join(installDir, "bin")},
+		Notify:      true,
+		After:       []string{"network.target", "local-fs.target"},
+		Systemd: []string{
+			"ExecStartPre=-/sbin/modprobe overlay",
+			"Delegate=yes",
+			"KillMode=process",
+			"LimitNPROC=infinity",
+			"LimitCORE=infinity",
+			"TasksMax=infinity",
+			"OOMScoreAdjust=-999",
+		},
+	}}
+	return spec, nil
+}
+
+func init() {
+	// 独立安装 containerd（不依赖 Docker），附带 runc、nerdctl 与 CNI 插件
+	soft.Register("containerd", staticbin.NewManager(buildSpec))
+}
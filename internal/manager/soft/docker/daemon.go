@@ -0,0 +1,153 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft"
+	"github.com/bookandmusic/dev-tools/internal/service"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// desiredDaemonConfig 由配置生成的 daemon.json 内容：registry-mirrors、代理以及 daemon 中声明的键，
+// daemon 中的键优先
+func (d *DockerManager) desiredDaemonConfig(cfg *config.DockerConfig) map[string]interface{} {
+	desired := map[string]interface{}{}
+	if len(cfg.RegistryMirrors) > 0 {
+		desired["registry-mirrors"] = cfg.RegistryMirrors
+	}
+	if cfg.HttpProxy != "" {
+		desired["proxies"] = map[string]interface{}{
+			"http-proxy":  cfg.HttpProxy,
+			"https-proxy": cfg.HttpProxy,
+		}
+	}
+	return utils.DeepMerge(desired, cfg.Daemon)
+}
+
+// renderDaemonConfig 读取当前 daemon.json 并与配置深度合并，返回当前内容与合并后的内容
+func (d *DockerManager) renderDaemonConfig(ctx context.Context, m installMode, cfg *config.DockerConfig) (string, string, error) {
+	current, err := utils.ReadFile(ctx, m.DaemonFile)
+	if err != nil && !os.IsNotExist(err) {
+		return "", "", fmt.Errorf("读取 %s 失败: %w", m.DaemonFile, err)
+	}
+
+	var orig map[string]interface{}
+	if len(strings.TrimSpace(string(current))) > 0 {
+		// 解析失败时不覆盖，避免丢失手工修改的内容
+		if err := json.Unmarshal(current, &orig); err != nil {
+			return "", "", fmt.Errorf("解析 %s 失败，请先修正文件格式: %w", m.DaemonFile, err)
+		}
+	}
+
+	merged, err := utils.MergeJSON(orig, d.desiredDaemonConfig(cfg))
+	if err != nil {
+		return "", "", err
+	}
+	return strings.TrimRight(string(current), "\n"), merged, nil
+}
+
+// writeDaemonConfig 合并并写入 daemon.json，写入前显示差异，返回文件是否有变化
+func (d *DockerManager) writeDaemonConfig(ctx context.Context, ui ui.UI, env map[string]string, m installMode, cfg *config.DockerConfig) (bool, error) {
+	current, merged, err := d.renderDaemonConfig(ctx, m, cfg)
+	if err != nil {
+		return false, err
+	}
+	diff := utils.UnifiedDiff(m.DaemonFile, current, merged)
+	if diff == "" {
+		ui.Info("%s 无需修改", m.DaemonFile)
+		return false, nil
+	}
+	// 干跑模式下计划动作中会输出同样的差异
+	if utils.DryRunFromContext(ctx) == nil {
+		ui.Println("%s", strings.TrimRight(diff, "\n"))
+	}
+
+	if m.Rootless {
+		if err := utils.MkdirAll(ctx, path.Dir(m.DaemonFile), 0o700); err != nil {
+			return false, err
+		}
+	}
+	ui.Info("写入 %s", m.DaemonFile)
+	if err := utils.TeeFile(ctx, ui, env, m.DaemonFile, merged, m.Sudo); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ShowConfig 输出合并后将写入的 daemon.json
+func (d *DockerManager) ShowConfig(ctx context.Context) error {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return err
+	}
+	m, err := d.mode(params.Cfg, params.Global)
+	if err != nil {
+		return err
+	}
+	_, merged, err := d.renderDaemonConfig(ctx, m, params.Cfg)
+	if err != nil {
+		return err
+	}
+	params.UI.Println("%s", merged)
+	return nil
+}
+
+// DiffConfig 对比当前 daemon.json 与合并后的内容
+func (d *DockerManager) DiffConfig(ctx context.Context) error {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return err
+	}
+	m, err := d.mode(params.Cfg, params.Global)
+	if err != nil {
+		return err
+	}
+	current, merged, err := d.renderDaemonConfig(ctx, m, params.Cfg)
+	if err != nil {
+		return err
+	}
+	if diff := utils.UnifiedDiff(m.DaemonFile, current, merged); diff != "" {
+		params.UI.Println("%s", strings.TrimRight(diff, "\n"))
+		return nil
+	}
+	params.UI.Success("%s 与配置一致", m.DaemonFile)
+	return nil
+}
+
+// ApplyConfig 写入 daemon.json，服务运行中时重新加载配置
+func (d *DockerManager) ApplyConfig(ctx context.Context) error {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return err
+	}
+	ui := params.UI
+	m, err := d.mode(params.Cfg, params.Global)
+	if err != nil {
+		return err
+	}
+
+	changed, err := d.writeDaemonConfig(ctx, ui, params.Env, m, params.Cfg)
+	if err != nil || !changed {
+		return err
+	}
+
+	if !m.Service.IsActive(ctx, m.Spec) {
+		ui.Success("已写入 %s，下次启动 Docker 时生效", m.DaemonFile)
+		return nil
+	}
+	ui.Info("重新加载 Docker 配置...")
+	if err := service.Run(ctx, ui, params.Env, m.Service, m.Spec, service.Reload); err != nil {
+		ui.Error("重新加载 Docker 配置失败")
+		return err
+	}
+	ui.Success("已应用 %s", m.DaemonFile)
+	ui.Warning("data-root、storage-driver、exec-opts 等配置需要重启才能生效: %s", m.command(service.Restart))
+	return nil
+}
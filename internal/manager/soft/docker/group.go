@@ -0,0 +1,174 @@
+package docker
+
+import (
+	"context"
+	"os/exec"
+	"os/user"
+	"slices"
+	"strings"
+
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/lock"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+const (
+	// dockerGroup 可以访问 docker.sock 的用户组，dockerd 默认将 socket 的属组设为该组
+	dockerGroup = "docker"
+	// dockerSocket 系统级 dockerd 监听的 socket
+	dockerSocket = "/run/docker.sock"
+)
+
+// lock 记录中 docker 组相关的键
+const (
+	extraGroupCreated = "group-created"
+	extraGroupUser    = "group-user"
+)
+
+// groupState 安装时 dev-tools 对 docker 组做的修改，卸载时只撤销这些修改
+type groupState struct {
+	Created bool   // 组由 dev-tools 创建
+	User    string // 由 dev-tools 加入组的用户
+}
+
+// loadGroupState 从 lock 记录中读取 docker 组的修改
+func loadGroupState(r lock.Record) groupState {
+	return groupState{Created: r.Extra[extraGroupCreated] == "true", User: r.Extra[extraGroupUser]}
+}
+
+// merge 合并上一次安装的记录，重新安装时组已存在，但仍应由 dev-tools 负责清理
+func (s groupState) merge(prev groupState) groupState {
+	s.Created = s.Created || prev.Created
+	if s.User == "" {
+		s.User = prev.User
+	}
+	return s
+}
+
+// save 写入 lock 记录
+func (s groupState) save(r *lock.Record) {
+	if !s.Created && s.User == "" {
+		return
+	}
+	if r.Extra == nil {
+		r.Extra = map[string]string{}
+	}
+	if s.Created {
+		r.Extra[extraGroupCreated] = "true"
+	}
+	if s.User != "" {
+		r.Extra[extraGroupUser] = s.User
+	}
+}
+
+// manageGroup 系统级安装且未关闭 manage-group 时管理 docker 组
+func manageGroup(cfg *config.DockerConfig) bool {
+	return !cfg.Rootless && (cfg.ManageGroup == nil || *cfg.ManageGroup)
+}
+
+// createGroupArgs 创建系统组；以下命令在没有 shadow-utils 时使用 busybox（Alpine）的 addgroup/delgroup
+func createGroupArgs(group string) []string {
+	if _, err := exec.LookPath("groupadd"); err == nil {
+		return []string{"groupadd", "--system", group}
+	}
+	return []string{"addgroup", "-S", group}
+}
+
+// deleteGroupArgs 删除组
+func deleteGroupArgs(group string) []string {
+	if _, err := exec.LookPath("groupdel"); err == nil {
+		return []string{"groupdel", group}
+	}
+	return []string{"delgroup", group}
+}
+
+// addToGroupArgs 将用户加入组
+func addToGroupArgs(username, group string) []string {
+	if _, err := exec.LookPath("usermod"); err == nil {
+		return []string{"usermod", "-aG", group, username}
+	}
+	return []string{"addgroup", username, group}
+}
+
+// removeFromGroupArgs 将用户移出组
+func removeFromGroupArgs(username, group string) []string {
+	if _, err := exec.LookPath("gpasswd"); err == nil {
+		return []string{"gpasswd", "-d", username, group}
+	}
+	return []string{"delgroup", username, group}
+}
+
+// sudoCommand 拼接为带 sudo 的 shell 命令，用于安装日志中的撤销命令
+func sudoCommand(args []string) string {
+	return "sudo " + strings.Join(args, " ")
+}
+
+// inGroup 判断用户是否已在组中
+func inGroup(u *user.User, group string) bool {
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return false
+	}
+	gids, err := u.GroupIds()
+	if err != nil {
+		return false
+	}
+	return slices.Contains(gids, g.Gid)
+}
+
+// setupDockerGroup 创建 docker 组并将当前用户加入，root 用户无需加入；返回实际做了哪些修改
+func (d *DockerManager) setupDockerGroup(ctx context.Context, ui ui.UI, env map[string]string, j *journal, cfg *config.DockerConfig) (groupState, error) {
+	var state groupState
+	if !manageGroup(cfg) {
+		return state, nil
+	}
+	if _, err := user.LookupGroup(dockerGroup); err != nil {
+		if err := j.command("创建 "+dockerGroup+" 组", sudoCommand(deleteGroupArgs(dockerGroup))); err != nil {
+			return state, err
+		}
+		ui.Info("创建 %s 组...", dockerGroup)
+		args := createGroupArgs(dockerGroup)
+		if err := utils.RunCommand(ctx, ui, env, "sudo", args...); err != nil {
+			ui.Error("创建 %s 组失败", dockerGroup)
+			return state, err
+		}
+		state.Created = true
+	}
+
+	currentUser, err := utils.GetCurrentUser()
+	if err != nil {
+		return state, err
+	}
+	if currentUser.Uid == "0" || inGroup(currentUser, dockerGroup) {
+		return state, nil
+	}
+	if err := j.command("将 "+currentUser.Username+" 加入 "+dockerGroup+" 组", sudoCommand(removeFromGroupArgs(currentUser.Username, dockerGroup))); err != nil {
+		return state, err
+	}
+	ui.Info("将用户 %s 加入 %s 组...", currentUser.Username, dockerGroup)
+	args := addToGroupArgs(currentUser.Username, dockerGroup)
+	if err := utils.RunCommand(ctx, ui, env, "sudo", args...); err != nil {
+		ui.Error("将用户 %s 加入 %s 组失败", currentUser.Username, dockerGroup)
+		return state, err
+	}
+	state.User = currentUser.Username
+	return state, nil
+}
+
+// removeDockerGroup 撤销安装时对 docker 组的修改：移出由 dev-tools 加入的用户，删除由 dev-tools 创建的组
+func (d *DockerManager) removeDockerGroup(ctx context.Context, ui ui.UI, env map[string]string, state groupState) {
+	if _, err := user.LookupGroup(dockerGroup); err != nil {
+		return
+	}
+	if state.User != "" {
+		if u, err := user.Lookup(state.User); err == nil && inGroup(u, dockerGroup) {
+			ui.Info("将用户 %s 移出 %s 组...", state.User, dockerGroup)
+			_ = utils.RunCommand(ctx, ui, env, "sudo", removeFromGroupArgs(state.User, dockerGroup)...)
+		}
+	}
+	if state.Created {
+		ui.Info("删除 %s 组...", dockerGroup)
+		_ = utils.RunCommand(ctx, ui, env, "sudo", deleteGroupArgs(dockerGroup)...)
+	}
+}
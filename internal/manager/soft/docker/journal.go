@@ -0,0 +1,222 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// journalStep 安装过程中的一个步骤及其撤销方式
+type journalStep struct {
+	Name   string   `json:"name"`
+	Path   string   `json:"path,omitempty"`   // 该步骤修改的文件或目录
+	Backup string   `json:"backup,omitempty"` // 修改前的备份，为空表示原先不存在
+	Sudo   bool     `json:"sudo,omitempty"`   // 恢复 Path 时是否需要 sudo
+	Undo   []string `json:"undo,omitempty"`   // 恢复文件后执行的 shell 命令
+}
+
+// journal 记录一次安装对系统做的修改，失败时按相反顺序撤销，也可通过 rollback 命令手动恢复
+type journal struct {
+	Version   string        `json:"version"`
+	StartedAt time.Time     `json:"started_at"`
+	Completed bool          `json:"completed"`
+	Rootless  bool          `json:"rootless,omitempty"` // rootless 安装的备份无需 sudo 删除
+	Steps     []journalStep `json:"steps"`
+
+	dir string
+	ui  ui.UI
+	env map[string]string
+}
+
+// journalDir 返回日志及备份所在目录
+func journalDir(global *config.CommonConfig) string {
+	return filepath.Join(utils.ExpandAbsDir(global.RootDir), "journal", "docker")
+}
+
+// beginJournal 清理上一次的日志并开始新的记录，干跑模式下返回 nil（nil journal 的方法均为空操作）
+func (d *DockerManager) beginJournal(ctx context.Context, ui ui.UI, env map[string]string, global *config.CommonConfig, version string, rootless bool) (*journal, error) {
+	if utils.DryRunFromContext(ctx) != nil {
+		return nil, nil
+	}
+	j := &journal{Version: version, StartedAt: time.Now(), Rootless: rootless, dir: journalDir(global), ui: ui, env: env}
+	// 备份中可能有 root 所有的文件，需要 sudo 删除
+	if err := j.removeDir(ctx); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Join(j.dir, "backup"), 0o700); err != nil {
+		return nil, err
+	}
+	return j, j.save()
+}
+
+// loadJournal 读取上一次安装留下的日志
+func loadJournal(ui ui.UI, env map[string]string, global *config.CommonConfig) (*journal, error) {
+	dir := journalDir(global)
+	data, err := os.ReadFile(filepath.Join(dir, "journal.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New("no docker install journal found, nothing to roll back")
+		}
+		return nil, err
+	}
+	j := &journal{dir: dir, ui: ui, env: env}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("failed to parse journal %s: %w", dir, err)
+	}
+	return j, nil
+}
+
+func (j *journal) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(j.dir, "journal.json"), data, 0o600)
+}
+
+// record 追加步骤并立即落盘，保证中途崩溃后仍能手动回滚
+func (j *journal) record(step journalStep) error {
+	if j == nil {
+		return nil
+	}
+	j.Steps = append(j.Steps, step)
+	return j.save()
+}
+
+// run 执行命令，sudo 为 true 时加上 sudo 前缀
+func (j *journal) run(ctx context.Context, sudo bool, args ...string) error {
+	if sudo {
+		args = append([]string{"sudo"}, args...)
+	}
+	return utils.RunCommand(ctx, j.ui, j.env, args[0], args[1:]...)
+}
+
+// backupPath 返回步骤对应的备份路径
+func (j *journal) backupPath(path string) string {
+	return filepath.Join(j.dir, "backup", fmt.Sprintf("%02d-%s", len(j.Steps), filepath.Base(path)))
+}
+
+// track 记录即将创建的路径，撤销时删除；路径已存在时不做记录
+func (j *journal) track(name, path string, sudo bool) error {
+	if j == nil || utils.PathExists(path) {
+		return nil
+	}
+	return j.record(journalStep{Name: name, Path: path, Sudo: sudo})
+}
+
+// backup 复制即将被修改的文件，撤销时恢复原文件（原先不存在则删除）
+func (j *journal) backup(ctx context.Context, name, path string, sudo bool, undo ...string) error {
+	if j == nil {
+		return nil
+	}
+	step := journalStep{Name: name, Path: path, Sudo: sudo, Undo: undo}
+	if utils.PathExists(path) {
+		step.Backup = j.backupPath(path)
+		if err := j.run(ctx, sudo, "cp", "-a", path, step.Backup); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	}
+	return j.record(step)
+}
+
+// move 将即将被替换的目录整体移到备份中，撤销时移回
+func (j *journal) move(ctx context.Context, name, path string, sudo bool) error {
+	if j == nil {
+		return nil
+	}
+	step := journalStep{Name: name, Path: path, Sudo: sudo}
+	if utils.PathExists(path) {
+		step.Backup = j.backupPath(path)
+		if err := j.run(ctx, sudo, "mv", path, step.Backup); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	}
+	return j.record(step)
+}
+
+// command 记录一个只需执行命令即可撤销的步骤（如启动服务）
+func (j *journal) command(name string, undo ...string) error {
+	if j == nil {
+		return nil
+	}
+	return j.record(journalStep{Name: name, Undo: undo})
+}
+
+// complete 标记安装成功，日志保留供 rollback 使用
+func (j *journal) complete() error {
+	if j == nil {
+		return nil
+	}
+	j.Completed = true
+	return j.save()
+}
+
+// rollback 按相反顺序撤销所有步骤，单个步骤失败不影响其余步骤，全部成功后删除日志
+func (j *journal) rollback(ctx context.Context) error {
+	if j == nil {
+		return nil
+	}
+	var errs []string
+	for i := len(j.Steps) - 1; i >= 0; i-- {
+		step := j.Steps[i]
+		j.ui.Info("撤销: %s", step.Name)
+		if err := j.undo(ctx, step); err != nil {
+			j.ui.Error("撤销 %s 失败: %v", step.Name, err)
+			errs = append(errs, fmt.Sprintf("%s: %v", step.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("rollback incomplete, journal kept in %s: %s", j.dir, strings.Join(errs, "; "))
+	}
+	return j.removeDir(ctx)
+}
+
+// needsSudo 撤销是否需要 sudo：系统级安装，或有步骤修改了需要 root 权限的路径
+func (j *journal) needsSudo() bool {
+	if !j.Rootless {
+		return true
+	}
+	for _, step := range j.Steps {
+		if step.Sudo {
+			return true
+		}
+	}
+	return false
+}
+
+// removeDir 删除日志目录，系统级安装的备份中可能有 root 所有的文件
+func (j *journal) removeDir(ctx context.Context) error {
+	return j.run(ctx, !j.Rootless, "rm", "-rf", j.dir)
+}
+
+func (j *journal) undo(ctx context.Context, step journalStep) error {
+	if step.Path != "" {
+		if err := j.run(ctx, step.Sudo, "rm", "-rf", step.Path); err != nil {
+			return err
+		}
+		if step.Backup != "" {
+			if err := j.run(ctx, step.Sudo, "mv", step.Backup, step.Path); err != nil {
+				return err
+			}
+		}
+	}
+	for _, cmd := range step.Undo {
+		// 服务管理方式不支持的动作没有对应命令
+		if cmd == "" {
+			continue
+		}
+		if err := utils.RunCommand(ctx, j.ui, j.env, "bash", "-c", cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,17 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"path"
 	"runtime"
 	"strings"
+	"time"
 
+	"github.com/bookandmusic/dev-tools/internal/bundle"
 	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/github"
+	"github.com/bookandmusic/dev-tools/internal/lock"
 	"github.com/bookandmusic/dev-tools/internal/manager/soft"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft/staticbin"
+	"github.com/bookandmusic/dev-tools/internal/service"
 	"github.com/bookandmusic/dev-tools/internal/ui"
 	"github.com/bookandmusic/dev-tools/internal/utils"
+	"github.com/bookandmusic/dev-tools/internal/version"
 )
 
+// DockerManager 安装 Docker 静态包。下载复用 staticbin.Installer.Fetch，
+// 但安装流程不使用 staticbin：每一步都要写入可回滚的日志，还要支持 rootless 模式、
+// 管理 docker 组并合并已有的 daemon.json，这些都不是通用流程能表达的
 type DockerManager struct{}
 
 func (d *DockerManager) Install(ctx context.Context) error {
@@ -29,55 +38,156 @@ func (d *DockerManager) Install(ctx context.Context) error {
 	cfg := params.Cfg
 	global := params.Global
 	env := params.Env
-	version := cfg.Version
 
-	// 检查sudo权限
-	if err = d.checkSudoPermissions(ctx, ui, env); err != nil {
+	// 使用 --bundle 时所有产物都从离线包读取
+	b, err := bundle.Open(ui, global.Bundle, global.CacheDir)
+	if err != nil {
+		return err
+	}
+	if b != nil {
+		ctx = bundle.WithBundle(ctx, b)
+	}
+
+	// 检查sudo权限，rootless 模式改为检查子 ID 等前置条件
+	if err = d.checkPermissions(ctx, ui, env, cfg); err != nil {
 		return err
 	}
 
 	// 获取版本和架构信息
-	arch, version, err := d.getVersionAndArch(ui, global, version)
+	arch, version, plugins, err := d.resolveTarget(ctx, ui, global, cfg)
 	if err != nil {
 		return err
 	}
 
-	// 获取插件版本
-	pluginVersions, err := d.getPluginVersions(ui, global, arch)
+	return d.install(ctx, ui, env, global, cfg, version, arch, plugins)
+}
+
+// resolveTarget 解析要安装的 Docker 版本、架构以及插件版本
+func (d *DockerManager) resolveTarget(ctx context.Context, ui ui.UI, global *config.CommonConfig, cfg *config.DockerConfig) (utils.ArchType, string, []pluginArtifact, error) {
+	arch, version, err := d.getVersionAndArch(ctx, ui, global, cfg.Version)
+	if err != nil {
+		return "", "", nil, err
+	}
+	plugins, err := d.getPluginVersions(ctx, ui, global, cfg, arch)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return arch, version, plugins, nil
+}
+
+// install 按解析好的版本安装，失败时自动回滚，成功后写入 lock 文件
+func (d *DockerManager) install(
+	ctx context.Context,
+	ui ui.UI,
+	env map[string]string,
+	global *config.CommonConfig,
+	cfg *config.DockerConfig,
+	version string,
+	arch utils.ArchType,
+	plugins []pluginArtifact,
+) error {
+	// 开始记录安装日志，之后的每一步都可以撤销
+	j, err := d.beginJournal(ctx, ui, env, global, version, cfg.Rootless)
+	if err != nil {
+		ui.Error("创建安装日志失败")
+		return err
+	}
+
+	var group groupState
+	if err = d.installSteps(ctx, ui, env, global, cfg, j, version, arch, plugins, &group); err != nil {
+		if j != nil {
+			ui.Warning("安装失败，按相反顺序撤销已执行的步骤...")
+			if rbErr := j.rollback(ctx); rbErr != nil {
+				ui.Error("自动回滚失败，可执行 dev-tools docker rollback 重试: %v", rbErr)
+			} else {
+				ui.Info("已恢复到安装前的状态")
+			}
+		}
+		return err
+	}
+	if err = j.complete(); err != nil {
+		ui.Warning("保存安装日志失败: %v", err)
+	}
+
+	// 记录实际安装的版本，以及 dev-tools 对 docker 组做的修改（重新安装时保留上一次的记录）
+	record := lock.Record{Version: version, Arch: string(arch), Components: map[string]string{}}
+	for _, plugin := range plugins {
+		record.Components[plugin.Name] = plugin.Version
+	}
+	if err = lock.Update(ctx, global.RootDir, func(l *lock.Lock) {
+		prev, _ := l.Get("docker")
+		group.merge(loadGroupState(prev)).save(&record)
+		l.Set("docker", record)
+	}); err != nil {
+		ui.Warning("更新 lock 文件失败: %v", err)
+	}
+
+	// 显示成功信息
+	d.showSuccessMessage(ui, cfg)
+
+	return nil
+}
+
+// installSteps 执行会修改系统的安装步骤，每一步都先写入日志
+func (d *DockerManager) installSteps(
+	ctx context.Context,
+	ui ui.UI,
+	env map[string]string,
+	global *config.CommonConfig,
+	cfg *config.DockerConfig,
+	j *journal,
+	version string,
+	arch utils.ArchType,
+	plugins []pluginArtifact,
+	group *groupState,
+) error {
+	m, err := d.mode(cfg, global)
 	if err != nil {
 		return err
 	}
+	ui.Info("服务管理方式: %s", m.Service.Name())
+
+	// 停止正在运行的服务
+	if err := d.stopDockerService(ctx, ui, env, j, m); err != nil {
+		return err
+	}
 
 	// 下载和安装Docker
-	installPath, binPath, err := d.downloadAndInstallDocker(ctx, ui, env, global, cfg, version, arch)
+	installPath, binPath, err := d.downloadAndInstallDocker(ctx, ui, env, global, cfg, j, m, version, arch)
 	if err != nil {
 		return err
 	}
 
+	// 创建 docker 组并加入当前用户
+	if *group, err = d.setupDockerGroup(ctx, ui, env, j, cfg); err != nil {
+		return err
+	}
+
 	// 生成配置文件
-	if err = d.generateConfigFiles(ctx, ui, env, binPath, cfg); err != nil {
+	if err = d.generateConfigFiles(ctx, ui, env, j, m, cfg); err != nil {
 		return err
 	}
 
 	// 安装插件
-	if err := d.installPlugins(ctx, ui, env, global, installPath, pluginVersions); err != nil {
+	if err = d.installPlugins(ctx, ui, env, global, j, m, installPath, plugins); err != nil {
 		return err
 	}
 
 	// 启动服务
-	if err = d.startDockerService(ctx, ui, env, binPath); err != nil {
+	if err = d.startDockerService(ctx, ui, env, j, m, binPath); err != nil {
 		return err
 	}
 
 	// 更新环境变量
-	if err = d.updateEnvironment(ui, global, binPath); err != nil {
-		return err
-	}
-
-	// 显示成功信息
-	d.showSuccessMessage(ui)
+	return d.updateEnvironment(ctx, ui, global, j, m, binPath)
+}
 
-	return nil
+// checkPermissions 系统级安装需要 sudo，rootless 安装需要满足子 ID 等前置条件
+func (d *DockerManager) checkPermissions(ctx context.Context, ui ui.UI, env map[string]string, cfg *config.DockerConfig) error {
+	if cfg.Rootless {
+		return d.checkRootlessPrerequisites(ui)
+	}
+	return d.checkSudoPermissions(ctx, ui, env)
 }
 
 func (d *DockerManager) checkSudoPermissions(ctx context.Context, ui ui.UI, env map[string]string) error {
@@ -86,201 +196,270 @@ func (d *DockerManager) checkSudoPermissions(ctx context.Context, ui ui.UI, env
 		ui.Error("获取sudo权限失败")
 		return err
 	}
+	return nil
+}
+
+// stopDockerService 停止正在运行的服务，回滚时重新启动
+func (d *DockerManager) stopDockerService(ctx context.Context, ui ui.UI, env map[string]string, j *journal, m installMode) error {
+	if !m.Service.IsActive(ctx, m.Spec) {
+		return nil
+	}
+	if err := j.command("停止 docker 服务", m.command(service.Start)); err != nil {
+		return err
+	}
 	ui.Info("尝试停止正在运行的Docker服务...")
-	_ = utils.RunCommand(ctx, ui, env, "sudo", "systemctl", "stop", "docker")
+	_ = service.Run(ctx, ui, env, m.Service, m.Spec, service.Stop)
 	return nil
 }
 
-func (d *DockerManager) getVersionAndArch(ui ui.UI, global *config.CommonConfig, version string) (utils.ArchType, string, error) {
+func (d *DockerManager) getVersionAndArch(ctx context.Context, ui ui.UI, global *config.CommonConfig, version string) (utils.ArchType, string, error) {
 	arch := utils.DetectArch()
 	if arch == utils.ArchUnknown {
 		ui.Error("工具只支持AMD64、AARCH64, 不支持当前主机架构: %s", runtime.GOARCH)
 		return "", "", errors.New("unknown architecture")
 	}
 
-	if version == "" {
-		ui.Info("获取Docker最新版本...")
-		versionStr, err := utils.GetLatestReleaseTag(ui, "moby/moby", global.GithubProxy, global.HttpProxy)
-		if err != nil {
-			ui.Error("获取Docker最新版本失败")
-			return "", "", err
+	if b := bundle.FromContext(ctx); b != nil {
+		if !b.Has("docker") {
+			return "", "", errors.New("离线包中没有 Docker，请使用 bundle create --only docker 重新生成")
 		}
-		version = strings.TrimPrefix(versionStr, "v")
+		if b.Arch != string(arch) {
+			return "", "", fmt.Errorf("离线包架构为 %s，与当前主机架构 %s 不符", b.Arch, arch)
+		}
+		version = b.Version("docker")
+	}
+	version, err := d.resolveVersion(ctx, ui, global, version)
+	if err != nil {
+		return "", "", err
 	}
 	ui.Info("准备安装 Docker %s, 架构: %s", version, arch)
 	return arch, version, nil
 }
 
-func (d *DockerManager) getPluginVersions(ui ui.UI, global *config.CommonConfig, arch utils.ArchType) (map[string]interface{}, error) {
-	composeVersion, err := utils.GetLatestReleaseTag(ui, "docker/compose", global.GithubProxy, global.HttpProxy)
-	if err != nil {
-		ui.Error("获取docker-compose最新版本失败")
-		return nil, err
+// resolveVersion 版本为 latest 或约束（如 "~26.1"）时在 moby/moby 的 release 中查找，离线模式下无法查询
+func (d *DockerManager) resolveVersion(ctx context.Context, ui ui.UI, global *config.CommonConfig, v string) (string, error) {
+	if version.IsExact(v) {
+		return strings.TrimPrefix(v, "v"), nil
+	}
+	if utils.IsOffline(ctx) {
+		return "", fmt.Errorf("离线模式下必须指定完整的 Docker 版本: %w", utils.ErrOffline)
 	}
-	buildxVersion, err := utils.GetLatestReleaseTag(ui, "docker/buildx", global.GithubProxy, global.HttpProxy)
+	ui.Info("获取Docker版本 %s...", v)
+	tag, err := github.New(ui, global).ResolveTag(ctx, "moby/moby", v, false)
 	if err != nil {
-		ui.Error("获取docker-buildx最新版本失败")
-		return nil, err
+		ui.Error("获取Docker版本失败")
+		return "", err
 	}
-	var buildxArch string
-	switch arch {
-	case utils.ArchX86_64:
-		buildxArch = "amd64"
-	default:
-		buildxArch = "arm64"
-	}
-	ui.Info("插件版本: compose=%s, buildx=%s (%s)", composeVersion, buildxVersion, buildxArch)
-
-	return map[string]interface{}{
-		"composeVersion": composeVersion,
-		"buildxVersion":  buildxVersion,
-		"buildxArch":     buildxArch,
-		"arch":           arch,
-	}, nil
+	return strings.TrimPrefix(tag, "v"), nil
 }
 
-func (d *DockerManager) downloadAndInstallDocker(ctx context.Context, ui ui.UI, env map[string]string, global *config.CommonConfig, cfg *config.DockerConfig, version string, arch utils.ArchType) (string, string, error) {
-	installPath := cfg.InstallDir
-	if installPath == "" {
-		installPath = path.Join(global.RootDir, "docker")
+// getPluginVersions 解析配置中每个插件的版本：离线包中记录的版本优先，
+// 其次是 compose-version/buildx-version 固定的版本，只有设为 latest 时才查询最新发布
+func (d *DockerManager) getPluginVersions(ctx context.Context, ui ui.UI, global *config.CommonConfig, cfg *config.DockerConfig, arch utils.ArchType) ([]pluginArtifact, error) {
+	b := bundle.FromContext(ctx)
+	artifacts := make([]pluginArtifact, 0, len(cfg.Plugins))
+	for _, plugin := range cfg.Plugins {
+		release, ok := pluginReleases[plugin]
+		if !ok {
+			return nil, fmt.Errorf("不支持的插件: %s（可选 compose、buildx）", plugin)
+		}
+
+		v := d.pinnedPluginVersion(cfg, plugin)
+		switch {
+		case b != nil:
+			if v = b.Version(release.Name); v == "" {
+				return nil, fmt.Errorf("离线包中没有插件 %s", release.Name)
+			}
+		case !version.IsExact(v):
+			if utils.IsOffline(ctx) {
+				return nil, fmt.Errorf("离线模式下无法查询 %s 版本 %s，请固定版本或使用 --bundle: %w", release.Name, v, utils.ErrOffline)
+			}
+			tag, err := github.New(ui, global).ResolveTag(ctx, release.Repo, v, false)
+			if err != nil {
+				ui.Error("获取%s版本失败", release.Name)
+				return nil, err
+			}
+			v = tag
+		default:
+			v = "v" + strings.TrimPrefix(v, "v")
+		}
+
+		artifacts = append(artifacts, pluginArtifact{
+			Name:    release.Name,
+			Version: v,
+			File:    release.File(v, arch),
+			BaseUrl: fmt.Sprintf("https://github.com/%s/releases/download", release.Repo),
+		})
+		ui.Info("插件版本: %s=%s", release.Name, v)
 	}
-	installPath = utils.ExpandAbsDir(installPath)
+	return artifacts, nil
+}
+
+func (d *DockerManager) downloadAndInstallDocker(ctx context.Context, ui ui.UI, env map[string]string, global *config.CommonConfig, cfg *config.DockerConfig, j *journal, m installMode, version string, arch utils.ArchType) (string, string, error) {
+	installPath := d.resolveInstallPath(cfg, global)
 	binPath := path.Join(installPath, "bin")
-	ui.Info("安装目录 %s ...", binPath)
-	if err := utils.CreateIfNotExists(ctx, ui, env, binPath, true); err != nil {
-		return "", "", err
+
+	// 先下载到缓存，下载失败时系统未被修改；rootless 模式还需要 rootless-extras 包
+	archives := []string{"docker"}
+	if m.Rootless {
+		archives = append(archives, rootlessExtras)
+	}
+	tarFilePaths := make([]string, 0, len(archives))
+	for _, name := range archives {
+		tarFilePath, err := d.dockerArchive(ctx, ui, env, global, cfg, name, version, arch)
+		if err != nil {
+			return "", "", err
+		}
+		tarFilePaths = append(tarFilePaths, tarFilePath)
 	}
 
-	tmpPath := path.Join(global.CacheDir, "docker", version)
-	tarFile := fmt.Sprintf("docker-%s.tgz", version)
-	tarFilePath := path.Join(tmpPath, tarFile)
-	if err := d.downloadIfNotExists(ui, arch, tarFilePath); err != nil {
+	// 旧的二进制整体移入备份，回滚时移回
+	if err := j.track("创建安装目录 "+installPath, installPath, m.Sudo); err != nil {
 		return "", "", err
 	}
-	ui.Info("解压文件:%s 到 %s", tarFile, binPath)
-	if err := utils.ExtractTarGzWithProgress(ui, tarFilePath, binPath, 1); err != nil {
-		ui.Error("解压Docker失败")
+	if err := j.move(ctx, "替换二进制目录 "+binPath, binPath, m.Sudo); err != nil {
 		return "", "", err
 	}
-	if err := d.chmodFiles(ctx, ui, env, binPath); err != nil {
+	ui.Info("安装目录 %s ...", binPath)
+	if err := utils.CreateIfNotExists(ctx, ui, env, binPath, m.Sudo); err != nil {
+		return "", "", err
+	}
+	for _, tarFilePath := range tarFilePaths {
+		ui.Info("解压文件:%s 到 %s", path.Base(tarFilePath), binPath)
+		if err := utils.ExtractTarGzWithProgress(ctx, ui, tarFilePath, binPath, 1); err != nil {
+			ui.Error("解压Docker失败")
+			return "", "", err
+		}
+	}
+	if err := staticbin.ChmodExecutables(ctx, ui, env, binPath, m.Sudo); err != nil {
 		return "", "", err
 	}
 
 	return installPath, binPath, nil
 }
 
-func (d *DockerManager) generateConfigFiles(ctx context.Context, ui ui.UI, env map[string]string, binPath string, cfg *config.DockerConfig) error {
-	if err := d.generateSystemdService(ctx, ui, env, binPath); err != nil {
+func (d *DockerManager) generateConfigFiles(ctx context.Context, ui ui.UI, env map[string]string, j *journal, m installMode, cfg *config.DockerConfig) error {
+	for _, file := range service.Files(m.Service, m.Spec) {
+		if err := j.backup(ctx, "写入 "+file, file, m.Sudo, m.command(service.Refresh)); err != nil {
+			return err
+		}
+	}
+	if err := service.Install(ctx, ui, env, m.Service, m.Spec); err != nil {
 		return err
 	}
-	if err := d.mergeJSONToFile(ctx, ui, env, "/etc/docker/daemon.json", map[string]interface{}{
-		"fixed-cidr-v6":       "fd00::/80",
-		"insecure-registries": []string{},
-		"ipv6":                true,
-		"proxies": map[string]string{
-			"http-proxy":  cfg.HttpProxy,
-			"https-proxy": cfg.HttpProxy,
-		},
-		"registry-mirrors": cfg.RegistryMirrors,
-	}, true); err != nil {
+
+	if err := j.backup(ctx, "合并 "+m.DaemonFile, m.DaemonFile, m.Sudo); err != nil {
 		return err
 	}
-	return nil
+	_, err := d.writeDaemonConfig(ctx, ui, env, m, cfg)
+	return err
 }
 
-func (d *DockerManager) installPlugins(ctx context.Context, ui ui.UI, env map[string]string, global *config.CommonConfig, installPath string, pluginVersions map[string]interface{}) error {
+func (d *DockerManager) installPlugins(ctx context.Context, ui ui.UI, env map[string]string, global *config.CommonConfig, j *journal, m installMode, installPath string, plugins []pluginArtifact) error {
 	pluginDir := path.Join(installPath, "plugins")
-	if err := utils.CreateIfNotExists(ctx, ui, env, pluginDir, true); err != nil {
-		ui.Error("创建插件目录失败")
+	if err := j.move(ctx, "替换插件目录 "+pluginDir, pluginDir, m.Sudo); err != nil {
 		return err
 	}
-
-	composeVersion := pluginVersions["composeVersion"].(string)
-	buildxVersion := pluginVersions["buildxVersion"].(string)
-	buildxArch := pluginVersions["buildxArch"].(string)
-	arch := pluginVersions["arch"].(string)
-
-	// 安装compose插件
-	if err := d.installPlugin(ctx, ui, env, global, pluginDir, "docker-compose", composeVersion,
-		fmt.Sprintf("docker-compose-linux-%s", arch),
-		"https://github.com/docker/compose/releases/download"); err != nil {
+	if err := utils.CreateIfNotExists(ctx, ui, env, pluginDir, m.Sudo); err != nil {
+		ui.Error("创建插件目录失败")
 		return err
 	}
 
-	// 安装buildx插件
-	if err := d.installPlugin(ctx, ui, env, global, pluginDir, "docker-buildx", buildxVersion,
-		fmt.Sprintf("buildx-%s.linux-%s", buildxVersion, buildxArch),
-		"https://github.com/docker/buildx/releases/download"); err != nil {
-		return err
+	// 安装配置的插件
+	for _, artifact := range plugins {
+		if err := d.installPlugin(ctx, ui, env, global, pluginDir, artifact); err != nil {
+			return err
+		}
 	}
 
 	// 更新 ~/.docker/config.json
-	if err := d.mergeJSONToFile(ctx, ui, env, utils.ExpandAbsDir("~/.docker/config.json"), map[string]interface{}{
+	clientConfigFile := utils.ExpandAbsDir("~/.docker/config.json")
+	if err := j.backup(ctx, "合并 "+clientConfigFile, clientConfigFile, false); err != nil {
+		return err
+	}
+	if err := d.mergeJSONToFile(ctx, ui, env, clientConfigFile, map[string]interface{}{
 		"cliPluginsExtraDirs": []string{pluginDir},
 	}, false); err != nil {
 		return err
 	}
-	if err := d.chmodFiles(ctx, ui, env, pluginDir); err != nil {
+	if err := staticbin.ChmodExecutables(ctx, ui, env, pluginDir, m.Sudo); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *DockerManager) installPlugin(ctx context.Context, ui ui.UI, env map[string]string, global *config.CommonConfig, pluginDir, name, version, pluginFile, baseUrl string) error {
-	cachePath := path.Join(global.CacheDir, "docker", fmt.Sprintf("%s-%s", name, version))
-	if err := utils.CreateIfNotExists(ctx, ui, env, cachePath, false); err != nil {
+func (d *DockerManager) installPlugin(ctx context.Context, ui ui.UI, env map[string]string, global *config.CommonConfig, pluginDir string, artifact pluginArtifact) error {
+	cachedFilePath, err := d.fetchPlugin(ctx, ui, env, global, artifact)
+	if err != nil {
 		return err
 	}
 
-	cachedFilePath := path.Join(cachePath, pluginFile)
-	if _, err := os.Stat(cachedFilePath); os.IsNotExist(err) {
-		// 缓存不存在，下载
-		downloadUrl := fmt.Sprintf("%s/%s/%s", baseUrl, version, pluginFile)
-		ui.Info("下载插件 %s 到缓存: %s", name, cachedFilePath)
-		if global.HttpProxy == "" {
-			downloadUrl = utils.ProxyURL(ui, global.GithubProxy, downloadUrl)
-		}
-		if err := utils.DownloadFileWithProgress(downloadUrl, cachedFilePath, ui, global.HttpProxy); err != nil {
-			return err
-		}
-	} else {
-		ui.Info("缓存已存在插件 %s, 跳过下载", name)
-	}
-
 	// 从缓存复制到插件目录
-	destPath := path.Join(pluginDir, name)
-	ui.Info("复制插件 %s 到 %s", name, destPath)
-	if err := utils.CopyFile(cachedFilePath, destPath); err != nil {
+	destPath := path.Join(pluginDir, artifact.Name)
+	ui.Info("复制插件 %s 到 %s", artifact.Name, destPath)
+	if err := utils.CopyFile(ctx, cachedFilePath, destPath); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *DockerManager) startDockerService(ctx context.Context, ui ui.UI, env map[string]string, binPath string) error {
+func (d *DockerManager) startDockerService(ctx context.Context, ui ui.UI, env map[string]string, j *journal, m installMode, binPath string) error {
+	// 回滚时停止服务，原先未启用的服务同时禁用
+	undo := []string{m.command(service.Stop)}
+	if !m.Service.IsEnabled(ctx, m.Spec) {
+		undo = append(undo, m.command(service.Disable))
+	}
+	if err := j.command("启动 docker 服务", undo...); err != nil {
+		return err
+	}
+
 	ui.Info("启动服务...")
-	if err := utils.RunCommand(
-		ctx, ui, env,
-		"bash", "-c",
-		"sudo systemctl daemon-reload && sudo systemctl enable docker && sudo systemctl start docker",
-	); err != nil {
+	if err := service.Run(ctx, ui, env, m.Service, m.Spec, service.Refresh, service.Enable, service.Start); err != nil {
 		ui.Error("启动服务失败")
 		return err
 	}
+	if m.Service.Name() == service.Supervised {
+		ui.Warning("当前系统没有可用的 init，Docker 不会开机自启，重启后请执行: %s", m.command(service.Start))
+	}
+
 	ui.Info("检测docker信息...")
-	if err := utils.RunCommand(ctx, ui, map[string]string{"PATH": binPath}, "docker", "info"); err != nil {
+	// 新加入 docker 组在当前会话中还未生效，系统级安装通过 sudo 检测
+	args := []string{path.Join(binPath, "docker")}
+	if m.Rootless {
+		args = append(args, "--host", dockerHost())
+	}
+	args = append(args, "info")
+	if m.Sudo {
+		args = append([]string{"sudo"}, args...)
+	}
+	// 只有 systemd 会等待 dockerd 就绪，其他方式启动后 socket 可能还未创建
+	if m.Service.Name() != service.Systemd && utils.DryRunFromContext(ctx) == nil {
+		for i := 0; i < 30; i++ {
+			if _, err := utils.CommandOutput(ctx, args[0], args[1:]...); err == nil {
+				break
+			}
+			time.Sleep(time.Second)
+		}
+	}
+	if err := utils.RunCommand(ctx, ui, env, args[0], args[1:]...); err != nil {
 		ui.Error("等待服务启动成功失败")
 		return err
 	}
 	return nil
 }
 
-func (d *DockerManager) updateEnvironment(ui ui.UI, global *config.CommonConfig, binPath string) error {
+func (d *DockerManager) updateEnvironment(ctx context.Context, ui ui.UI, global *config.CommonConfig, j *journal, m installMode, binPath string) error {
 	envFile := path.Join(global.RootDir, ".env")
-	err := utils.UpdateEnvFile(envFile, map[string]string{
-		"PATH": binPath,
-	}, "add")
+	if err := j.backup(ctx, "更新 "+envFile, envFile, false); err != nil {
+		return err
+	}
+	vars := map[string]string{"PATH": binPath}
+	if m.Rootless {
+		vars["DOCKER_HOST"] = dockerHost()
+	}
+	err := utils.UpdateEnvFile(ctx, envFile, vars, "add")
 	if err != nil {
 		ui.Error("添加环境变量失败")
 		return err
@@ -288,14 +467,25 @@ func (d *DockerManager) updateEnvironment(ui ui.UI, global *config.CommonConfig,
 	return nil
 }
 
-func (d *DockerManager) showSuccessMessage(ui ui.UI) {
+func (d *DockerManager) showSuccessMessage(ui ui.UI, cfg *config.DockerConfig) {
 	ui.Success("成功安装Docker")
-	currentUser, _ := utils.GetCurrentUser()
+	currentUser, err := utils.GetCurrentUser()
+	if err != nil {
+		// 无法确定当前用户时跳过与用户相关的提示
+		ui.Warning("获取当前用户失败: %v", err)
+		ui.Info("安装完成！请重新打开终端后使用 docker")
+		return
+	}
 	homeDir, shell, _ := utils.GetUserHomeAndShell(currentUser.Username)
 	profilePath := utils.GetProfilePath(shell, homeDir)
 	ui.Success("安装完成！请执行以下操作：")
 	ui.Info("1. 重新打开终端")
 	ui.Info("2. 运行: source %s", profilePath)
+	if cfg.Rootless {
+		ui.Info("3. 退出登录后保持 Docker 运行: loginctl enable-linger %s", currentUser.Username)
+	} else if manageGroup(cfg) && currentUser.Uid != "0" {
+		ui.Info("3. 重新登录或执行 newgrp %s 后，无需 sudo 即可使用 docker", dockerGroup)
+	}
 }
 
 func (d *DockerManager) Uninstall(ctx context.Context) error {
@@ -308,69 +498,252 @@ func (d *DockerManager) Uninstall(ctx context.Context) error {
 	global := params.Global
 	env := params.Env
 
-	installPath := cfg.InstallDir
-	if installPath == "" {
-		installPath = path.Join(global.RootDir, "docker")
+	installPath := d.resolveInstallPath(cfg, global)
+	m, err := d.mode(cfg, global)
+	if err != nil {
+		return err
+	}
+	// run 按安装模式决定是否加 sudo
+	run := func(args ...string) {
+		if m.Sudo {
+			args = append([]string{"sudo"}, args...)
+		}
+		_ = utils.RunCommand(ctx, ui, env, args[0], args[1:]...)
 	}
-	installPath = utils.ExpandAbsDir(installPath)
 
 	ui.Info("停止并禁用 Docker 服务...")
-	_ = utils.RunCommand(ctx, ui, env, "sudo", "systemctl", "stop", "docker")
-	_ = utils.RunCommand(ctx, ui, env, "sudo", "systemctl", "disable", "docker")
+	for _, action := range []service.Action{service.Stop, service.Disable} {
+		_ = service.Run(ctx, ui, env, m.Service, m.Spec, action)
+	}
+
+	// 删除服务文件
+	_ = service.Remove(ctx, ui, env, m.Service, m.Spec)
+	_ = service.Run(ctx, ui, env, m.Service, m.Spec, service.Refresh)
 
-	// 删除 service 文件
-	ui.Info("删除 docker.service 文件...")
-	serviceFile := "/etc/systemd/system/docker.service"
-	if utils.PathExists(serviceFile) {
-		ui.Info("删除 service 文件: %s", serviceFile)
-		_ = utils.RunCommand(ctx, ui, env, "sudo", "rm", "-f", serviceFile)
+	// 只撤销安装时记录的 docker 组修改
+	if l, err := lock.Open(global.RootDir); err == nil {
+		record, _ := l.Get("docker")
+		d.removeDockerGroup(ctx, ui, env, loadGroupState(record))
 	}
-	_ = utils.RunCommand(ctx, ui, env, "sudo", "systemctl", "daemon-reload")
 
 	// 删除 daemon.json
-	daemonFile := "/etc/docker/daemon.json"
-	if utils.PathExists(daemonFile) {
-		ui.Info("删除 daemon.json 文件: %s", daemonFile)
-		_ = utils.RunCommand(ctx, ui, env, "sudo", "rm", "-f", daemonFile)
+	if utils.PathExists(m.DaemonFile) {
+		ui.Info("删除 daemon.json 文件: %s", m.DaemonFile)
+		run("rm", "-f", m.DaemonFile)
 	}
 
 	// 删除安装目录
 	if utils.PathExists(installPath) {
 		ui.Info("删除安装目录: %s", installPath)
-		_ = utils.RunCommand(ctx, ui, env, "sudo", "rm", "-rf", installPath)
+		run("rm", "-rf", installPath)
 	}
 
 	// 删除用户配置 ~/.docker/config.json 中的插件路径
 	clientConfigFile := utils.ExpandAbsDir("~/.docker/config.json")
 	if utils.PathExists(clientConfigFile) {
 		ui.Info("清理用户配置文件: %s", clientConfigFile)
-		_ = utils.RunCommand(ctx, ui, env, "sudo", "rm", "-rf", clientConfigFile)
+		run("rm", "-rf", clientConfigFile)
 	}
 	envFile := path.Join(global.RootDir, ".env")
-	err = utils.UpdateEnvFile(envFile, map[string]string{
-		"PATH": path.Join(installPath, "bin"),
-	}, "remove")
+	vars := map[string]string{"PATH": path.Join(installPath, "bin")}
+	if m.Rootless {
+		vars["DOCKER_HOST"] = ""
+	}
+	err = utils.UpdateEnvFile(ctx, envFile, vars, "remove")
 	if err != nil {
 		ui.Error("删除环境变量失败")
 		return err
 	}
 
+	if err = lock.Update(ctx, global.RootDir, func(l *lock.Lock) { l.Delete("docker") }); err != nil {
+		ui.Warning("更新 lock 文件失败: %v", err)
+	}
+
 	ui.Success("Docker 卸载完成")
 	return nil
 }
 
-// 更新 Docker
+// Plan 对比已安装版本与配置版本，供 sync 使用
+func (d *DockerManager) Plan(ctx context.Context) (soft.Action, string, error) {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return "", "", err
+	}
+	cfg := params.Cfg
+
+	installPath := d.resolveInstallPath(cfg, params.Global)
+	current := d.installedVersion(ctx, path.Join(installPath, "bin"))
+	switch {
+	case current == "":
+		return soft.ActionInstall, "docker not installed", nil
+	case !version.Match(cfg.Version, current):
+		return soft.ActionUpdate, fmt.Sprintf("docker %s installed, want %s", current, cfg.Version), nil
+	}
+
+	// 只比较固定了版本的插件，latest 需要联网查询，交给 update 处理
+	installed := map[string]string{}
+	for _, component := range d.pluginComponents(ctx, path.Join(installPath, "plugins")) {
+		installed[component.Name] = component.Version
+	}
+	for _, plugin := range cfg.Plugins {
+		release, ok := pluginReleases[plugin]
+		want := d.pinnedPluginVersion(cfg, plugin)
+		if !ok || version.IsLatest(want) {
+			continue
+		}
+		if have := installed[release.Name]; !version.Match(want, have) {
+			return soft.ActionUpdate, fmt.Sprintf("%s %s installed, want %s", release.Name, orDash(have), want), nil
+		}
+	}
+
+	switch {
+	case version.IsLatest(cfg.Version):
+		return soft.ActionSkip, fmt.Sprintf("docker %s installed, version not pinned", current), nil
+	case !version.IsExact(cfg.Version):
+		return soft.ActionSkip, fmt.Sprintf("docker %s installed, satisfies %s", current, cfg.Version), nil
+	}
+	return soft.ActionSkip, fmt.Sprintf("docker %s up to date", current), nil
+}
+
+// Status 报告安装目录中的二进制、服务状态以及插件版本
+func (d *DockerManager) Status(ctx context.Context) (*soft.Status, error) {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return nil, err
+	}
+	installPath := d.resolveInstallPath(params.Cfg, params.Global)
+	binPath := path.Join(installPath, "bin")
+
+	status := &soft.Status{Name: "docker", Path: installPath}
+	status.Version = d.installedVersion(ctx, binPath)
+	status.Installed = status.Version != ""
+	if !status.Installed {
+		return status, nil
+	}
+
+	status.Components = append(status.Components, d.binaryComponents(ctx, binPath)...)
+	if m, err := d.mode(params.Cfg, params.Global); err == nil {
+		status.Components = append(status.Components, d.serviceComponent(ctx, m))
+	}
+	status.Components = append(status.Components, d.pluginComponents(ctx, path.Join(installPath, "plugins"))...)
+	return status, nil
+}
+
+// Bundle 解析版本并将 Docker 静态包及配置的插件写入离线包
+func (d *DockerManager) Bundle(ctx context.Context, w *bundle.Writer) error {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return err
+	}
+	ui := params.UI
+	global := params.Global
+	arch := utils.ArchType(w.Arch())
+
+	version, err := d.resolveVersion(ctx, ui, global, params.Cfg.Version)
+	if err != nil {
+		return err
+	}
+	plugins, err := d.getPluginVersions(ctx, ui, global, params.Cfg, arch)
+	if err != nil {
+		return err
+	}
+
+	w.SetVersion("docker", version)
+	archives := []string{"docker"}
+	if params.Cfg.Rootless {
+		archives = append(archives, rootlessExtras)
+	}
+	for _, name := range archives {
+		tarFilePath, err := d.dockerArchive(ctx, ui, params.Env, global, params.Cfg, name, version, arch)
+		if err != nil {
+			return err
+		}
+		if err := w.AddFile(name, tarFilePath, path.Join("docker", path.Base(tarFilePath))); err != nil {
+			return err
+		}
+	}
+
+	for _, artifact := range plugins {
+		file, err := d.fetchPlugin(ctx, ui, params.Env, global, artifact)
+		if err != nil {
+			return err
+		}
+		w.SetVersion(artifact.Name, artifact.Version)
+		if err := w.AddFile(artifact.Name, file, path.Join("docker", artifact.File)); err != nil {
+			return err
+		}
+	}
+	ui.Success("已加入 Docker %s (%s)", version, arch)
+	return nil
+}
+
+// Rollback 按上一次安装留下的日志撤销修改，用于自动回滚失败或需要退回旧版本时
+func (d *DockerManager) Rollback(ctx context.Context) error {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return err
+	}
+	ui := params.UI
+	env := params.Env
+
+	j, err := loadJournal(ui, env, params.Global)
+	if err != nil {
+		return err
+	}
+	if j.Completed {
+		ui.Info("回滚 Docker %s 的安装（%s，共 %d 步）", j.Version, j.StartedAt.Format("2006-01-02 15:04:05"), len(j.Steps))
+	} else {
+		ui.Warning("上一次安装 Docker %s 未完成，撤销已执行的 %d 步", j.Version, len(j.Steps))
+	}
+	// rootless 安装的步骤都在用户目录下，无需 sudo
+	if j.needsSudo() {
+		if err := d.checkSudoPermissions(ctx, ui, env); err != nil {
+			return err
+		}
+	}
+	if err := j.rollback(ctx); err != nil {
+		ui.Error("回滚 Docker 失败")
+		return err
+	}
+	ui.Success("Docker 已回滚")
+	return nil
+}
+
+// Update 对比当前版本与目标版本，有差异时重新安装
 func (d *DockerManager) Update(ctx context.Context) error {
 	params, err := soft.Parse[BaseParams](ctx)
 	if err != nil {
 		return err
 	}
 	ui := params.UI
+	cfg := params.Cfg
+	global := params.Global
 
 	ui.Info("开始更新 Docker...")
 
-	// 这里直接走 Install，Install 会覆盖已有文件 & 合并配置
-	if err := d.Install(ctx); err != nil {
+	b, err := bundle.Open(ui, global.Bundle, global.CacheDir)
+	if err != nil {
+		return err
+	}
+	if b != nil {
+		ctx = bundle.WithBundle(ctx, b)
+	}
+
+	arch, version, plugins, err := d.resolveTarget(ctx, ui, global, cfg)
+	if err != nil {
+		return err
+	}
+
+	if !d.showVersionDiff(ctx, ui, cfg, global, version, plugins) {
+		ui.Success("Docker 及插件已是目标版本，无需更新")
+		return nil
+	}
+
+	if err = d.checkPermissions(ctx, ui, params.Env, cfg); err != nil {
+		return err
+	}
+	// 这里直接走安装流程，会覆盖已有文件 & 合并配置
+	if err := d.install(ctx, ui, params.Env, global, cfg, version, arch, plugins); err != nil {
 		ui.Error("更新 Docker 失败: %v", err)
 		return err
 	}
@@ -0,0 +1,158 @@
+package docker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/service"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// rootlessExtras rootless 模式额外需要的静态包（dockerd-rootless.sh、rootlesskit 等）
+const rootlessExtras = "docker-rootless-extras"
+
+// minSubIDs rootless Docker 至少需要的子 UID/GID 数量
+const minSubIDs = 65536
+
+// installMode 区分系统级安装与 rootless 安装所用的文件位置、服务管理方式和命令
+type installMode struct {
+	Rootless   bool
+	Sudo       bool            // 写文件、执行命令时是否需要 sudo
+	DaemonFile string          // daemon.json
+	Service    service.Manager // systemd、OpenRC、SysV 或直接托管进程
+	Spec       service.Spec    // dockerd 服务描述
+}
+
+// mode 根据配置返回安装模式，service-manager 为 auto 时自动探测当前系统的 init
+func (d *DockerManager) mode(cfg *config.DockerConfig, global *config.CommonConfig) (installMode, error) {
+	svc, err := service.New(cfg.ServiceManager, cfg.Rootless)
+	if err != nil {
+		return installMode{}, err
+	}
+	installPath := d.resolveInstallPath(cfg, global)
+	binPath := path.Join(installPath, "bin")
+	spec := service.Spec{
+		Name:        "docker",
+		Description: "Docker Application Container Engine",
+		Exec:        path.Join(binPath, "dockerd"),
+		Env:         map[string]string{"PATH": binPath},
+		WorkDir:     binPath,
+		Notify:      true,
+		RuntimeDir:  path.Join(installPath, "run"),
+	}
+	if !cfg.Rootless {
+		// systemd 通过 docker.socket 创建属于 docker 组的 socket，其他方式由 dockerd 自己设置属组
+		if manageGroup(cfg) && svc.Name() == service.Systemd {
+			spec.Args = []string{"-H", "fd://"}
+			spec.Socket = &service.Socket{Listen: dockerSocket, Mode: "0660", Group: dockerGroup}
+		}
+		return installMode{Sudo: true, DaemonFile: daemonFile, Service: svc, Spec: spec}, nil
+	}
+
+	spec.Description += " (Rootless)"
+	spec.Exec = path.Join(binPath, "dockerd-rootless.sh")
+	spec.User = true
+	spec.Systemd = []string{
+		"TimeoutSec=0",
+		"RestartSec=2",
+		"LimitNOFILE=infinity",
+		"LimitNPROC=infinity",
+		"LimitCORE=infinity",
+		"TasksMax=infinity",
+		"Delegate=yes",
+		"NotifyAccess=all",
+		"KillMode=mixed",
+	}
+	return installMode{
+		Rootless:   true,
+		DaemonFile: utils.ExpandAbsDir("~/.config/docker/daemon.json"),
+		Service:    svc,
+		Spec:       spec,
+	}, nil
+}
+
+// command 返回执行服务动作的 shell 命令
+func (m installMode) command(actions ...service.Action) string {
+	return service.Commands(m.Service, m.Spec, actions...)
+}
+
+// dockerHost rootless 守护进程监听的 socket
+func dockerHost() string {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+	}
+	return "unix://" + filepath.Join(runtimeDir, "docker.sock")
+}
+
+// checkRootlessPrerequisites 检查 rootless 模式的前置条件：非 root 用户、newuidmap/newgidmap、
+// /etc/subuid 与 /etc/subgid 中的子 ID 范围以及 XDG_RUNTIME_DIR
+func (d *DockerManager) checkRootlessPrerequisites(ui ui.UI) error {
+	ui.Info("检查 rootless 前置条件...")
+	if os.Getuid() == 0 {
+		return errors.New("rootless 模式需要以普通用户运行，root 用户请关闭 rootless 选项")
+	}
+	currentUser, err := utils.GetCurrentUser()
+	if err != nil {
+		return err
+	}
+
+	for _, tool := range []string{"newuidmap", "newgidmap"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			return fmt.Errorf("找不到 %s，请让管理员安装 uidmap 软件包（Debian/Ubuntu: apt install uidmap，RHEL/Fedora: dnf install shadow-utils）", tool)
+		}
+	}
+
+	for _, file := range []string{"/etc/subuid", "/etc/subgid"} {
+		count, err := subIDCount(file, currentUser.Username, currentUser.Uid)
+		if err != nil {
+			return fmt.Errorf("读取 %s 失败: %w", file, err)
+		}
+		if count == 0 {
+			return fmt.Errorf("%s 中没有用户 %s 的子 ID 范围，请让管理员执行: echo \"%s:100000:%d\" | sudo tee -a %s",
+				file, currentUser.Username, currentUser.Username, minSubIDs, file)
+		}
+		if count < minSubIDs {
+			return fmt.Errorf("%s 中用户 %s 只有 %d 个子 ID，rootless Docker 至少需要 %d 个", file, currentUser.Username, count, minSubIDs)
+		}
+	}
+
+	if os.Getenv("XDG_RUNTIME_DIR") == "" {
+		ui.Warning("未设置 XDG_RUNTIME_DIR，用户级 systemd 可能不可用，请通过 ssh 或图形会话登录后再安装")
+	}
+	return nil
+}
+
+// subIDCount 统计 /etc/subuid 或 /etc/subgid 中分配给用户（按用户名或 UID 匹配）的子 ID 数量
+func subIDCount(file, username, uid string) (int, error) {
+	f, err := os.Open(filepath.Clean(file))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	total := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), ":")
+		if len(fields) != 3 || (fields[0] != username && fields[0] != uid) {
+			continue
+		}
+		if count, err := strconv.Atoi(fields[2]); err == nil {
+			total += count
+		}
+	}
+	return total, scanner.Err()
+}
@@ -6,69 +6,262 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
+	"github.com/bookandmusic/dev-tools/internal/bundle"
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft/staticbin"
+	"github.com/bookandmusic/dev-tools/internal/service"
 	"github.com/bookandmusic/dev-tools/internal/ui"
 	"github.com/bookandmusic/dev-tools/internal/utils"
+	"github.com/bookandmusic/dev-tools/internal/version"
 )
 
 // ========== 辅助子方法 ==========
 
-// 下载Docker包
-func (d *DockerManager) downloadIfNotExists(ui ui.UI, arch utils.ArchType, tarFilePath string) error {
-	ui.Info("下载Docker安装包...")
-	tmpPath := filepath.Dir(tarFilePath)
-	tarFile := filepath.Base(tarFilePath)
-	if !utils.PathExists(tarFilePath) {
-		url := fmt.Sprintf("https://mirrors.aliyun.com/docker-ce/linux/static/stable/%s/%s", arch, tarFile)
-		if err := utils.DownloadFileWithProgress(url, tarFilePath, ui, ""); err != nil {
-			ui.Error("下载Docker失败")
-			return err
+const (
+	daemonFile = "/etc/docker/daemon.json"
+)
+
+// pluginArtifact CLI 插件在 GitHub release 中的发布文件
+type pluginArtifact struct {
+	Name    string // 安装后的文件名，如 docker-compose
+	Version string
+	File    string // release 中的文件名
+	BaseUrl string
+}
+
+// pluginRelease CLI 插件的 GitHub 仓库与发布文件命名规则
+type pluginRelease struct {
+	Name string // 安装后的文件名
+	Repo string
+	File func(version string, arch utils.ArchType) string
+}
+
+// pluginReleases 支持的插件，键为配置 plugins 中的名称
+var pluginReleases = map[string]pluginRelease{
+	"compose": {
+		Name: "docker-compose",
+		Repo: "docker/compose",
+		File: func(version string, arch utils.ArchType) string {
+			return fmt.Sprintf("docker-compose-linux-%s", arch)
+		},
+	},
+	"buildx": {
+		Name: "docker-buildx",
+		Repo: "docker/buildx",
+		File: func(version string, arch utils.ArchType) string {
+			goArch := "arm64"
+			if arch == utils.ArchX86_64 {
+				goArch = "amd64"
+			}
+			return fmt.Sprintf("buildx-%s.linux-%s", version, goArch)
+		},
+	},
+}
+
+// pinnedPluginVersion 返回配置中固定的插件版本
+func (d *DockerManager) pinnedPluginVersion(cfg *config.DockerConfig, plugin string) string {
+	switch plugin {
+	case "compose":
+		return cfg.ComposeVersion
+	case "buildx":
+		return cfg.BuildxVersion
+	}
+	return ""
+}
+
+// fetcher 返回静态二进制安装器，只复用其下载、校验与缓存索引流程
+func (d *DockerManager) fetcher(ui ui.UI, env map[string]string, global *config.CommonConfig) *staticbin.Installer {
+	return &staticbin.Installer{UI: ui, Env: env, Global: global}
+}
+
+// fetchPlugin 返回插件文件路径：使用离线包时直接取包内文件，否则校验后下载到缓存
+func (d *DockerManager) fetchPlugin(ctx context.Context, ui ui.UI, env map[string]string, global *config.CommonConfig, artifact pluginArtifact) (string, error) {
+	downloadUrl := fmt.Sprintf("%s/%s/%s", artifact.BaseUrl, artifact.Version, artifact.File)
+	checksum := ""
+	if bundle.FromContext(ctx) == nil {
+		checksum = d.pluginChecksum(ctx, ui, global, artifact.BaseUrl, artifact.Version, artifact.File)
+	}
+	// 插件文件名中已带架构，缓存目录不再区分
+	return d.fetcher(ui, env, global).Fetch(ctx, &staticbin.Spec{Name: "docker"}, staticbin.Artifact{
+		Name:    artifact.Name,
+		Version: artifact.Version,
+		File:    artifact.File,
+		URLs:    utils.GithubURLs(ui, global.GithubProxy, global.HttpProxy, downloadUrl),
+		SHA256:  checksum,
+	})
+}
+
+// dockerArchive 返回静态包路径，name 为 docker 或 docker-rootless-extras：
+// 使用离线包时直接取包内文件，否则按配置的镜像顺序下载到缓存，并使用配置中固定的 checksum 校验
+func (d *DockerManager) dockerArchive(ctx context.Context, ui ui.UI, env map[string]string, global *config.CommonConfig, cfg *config.DockerConfig, name, version string, arch utils.ArchType) (string, error) {
+	tarFile := fmt.Sprintf("%s-%s.tgz", name, version)
+	urls := make([]string, 0, len(cfg.Mirrors))
+	for _, mirror := range cfg.Mirrors {
+		urls = append(urls, fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(mirror, "/"), arch, tarFile))
+	}
+	checksum := utils.PinnedChecksum(global.Checksums, fmt.Sprintf("/%s/%s", arch, tarFile))
+	if checksum == "" && bundle.FromContext(ctx) == nil {
+		ui.Warning("配置中没有 %s/%s 的 checksum，无法校验安装包", arch, tarFile)
+	}
+	// 镜像站已在国内，不使用 HTTP 代理
+	return d.fetcher(ui, env, global).Fetch(ctx, &staticbin.Spec{Name: "docker", Arch: arch}, staticbin.Artifact{
+		Name:    name,
+		Version: version,
+		File:    tarFile,
+		URLs:    urls,
+		SHA256:  checksum,
+		Direct:  true,
+	})
+}
+
+// pluginChecksum 获取插件的 SHA256：优先使用配置中固定的值，否则读取 GitHub release 发布的 checksums.txt 或 <file>.sha256
+// 获取失败时返回空字符串（不校验）
+func (d *DockerManager) pluginChecksum(ctx context.Context, ui ui.UI, global *config.CommonConfig, baseUrl, version, pluginFile string) string {
+	releaseUrl := fmt.Sprintf("%s/%s", baseUrl, version)
+	if checksum := utils.PinnedChecksum(global.Checksums, releaseUrl+"/"+pluginFile); checksum != "" {
+		return checksum
+	}
+	for _, name := range []string{"checksums.txt", pluginFile + ".sha256"} {
+		checksumUrl := releaseUrl + "/" + name
+		if global.HttpProxy == "" {
+			checksumUrl = utils.ProxyURL(ui, global.GithubProxy, checksumUrl)
+		}
+		content, err := utils.FetchText(ctx, checksumUrl, global.DownloadOptions(""))
+		if err != nil {
+			continue
+		}
+		if checksum, err := utils.ParseChecksums(content, pluginFile); err == nil {
+			return checksum
 		}
-	} else {
-		ui.Info("缓存目录%s已存在%s,跳过下载", tmpPath, tarFile)
 	}
-	return nil
+	ui.Warning("无法获取 %s 的 checksum，跳过校验", pluginFile)
+	return ""
 }
 
-// 设置执行权限
-func (d *DockerManager) chmodFiles(ctx context.Context, ui ui.UI, env map[string]string, dir string) error {
-	ui.Info("添加可执行权限...")
-	if err := utils.RunCommand(
-		ctx, ui, env,
-		"sudo", "bash", "-c",
-		fmt.Sprintf("find %s -maxdepth 1 -type f -exec chmod +x {} \\;", dir),
-	); err != nil {
-		ui.Error("设置执行权限失败")
-		return err
+// resolveInstallPath 返回 Docker 安装目录的绝对路径
+func (d *DockerManager) resolveInstallPath(cfg *config.DockerConfig, global *config.CommonConfig) string {
+	installPath := cfg.InstallDir
+	if installPath == "" {
+		installPath = filepath.Join(global.RootDir, "docker")
 	}
-	return nil
+	return utils.ExpandAbsDir(installPath)
 }
 
-// 生成 systemd service
-func (d *DockerManager) generateSystemdService(ctx context.Context, ui ui.UI, env map[string]string, binPath string) error {
-	serviceFile := "/etc/systemd/system/docker.service"
-	ui.Info("正在生成%s文件...", serviceFile)
-	serviceTem := fmt.Sprintf(`
-[Unit]
-Description=Docker Application Container Engine
-After=network.target
+// versionPattern 匹配 "Docker version 26.1.0, build a5ee5b1" 中的版本号
+var versionPattern = regexp.MustCompile(`(\d+\.\d+\.\d+)`)
 
-[Service]
-Type=notify
-WorkingDirectory=%s
-Environment="PATH=%s:/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
-ExecStart=%s/dockerd
-ExecReload=/bin/kill -s HUP \$MAINPID
-Restart=always
-StartLimitBurst=3
-StartLimitIntervalSec=60
+// installedVersion 读取安装目录下 docker 客户端的版本，未安装时返回空字符串
+func (d *DockerManager) installedVersion(ctx context.Context, binPath string) string {
+	dockerBin := filepath.Join(binPath, "docker")
+	if !utils.PathExists(dockerBin) {
+		return ""
+	}
+	out, err := utils.CommandOutput(ctx, dockerBin, "--version")
+	if err != nil {
+		return ""
+	}
+	return versionPattern.FindString(out)
+}
 
-[Install]
-WantedBy=multi-user.target
-`, binPath, binPath, binPath)
-	cmd := fmt.Sprintf("cat <<'EOF' | sudo tee %s > /dev/null\n%s\nEOF", serviceFile, serviceTem)
-	return utils.RunCommand(ctx, ui, env, "bash", "-c", cmd)
+// binaryComponents 列出 bin 目录下的二进制文件及其版本
+func (d *DockerManager) binaryComponents(ctx context.Context, binPath string) []soft.Component {
+	entries, err := os.ReadDir(binPath)
+	if err != nil {
+		return nil
+	}
+	// 只有这些二进制支持 --version
+	versioned := map[string]bool{"docker": true, "dockerd": true, "containerd": true, "runc": true, "ctr": true}
+
+	var components []soft.Component
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		binFile := filepath.Join(binPath, entry.Name())
+		component := soft.Component{Name: entry.Name(), State: "present", Path: binFile}
+		if versioned[entry.Name()] {
+			if out, err := utils.CommandOutput(ctx, binFile, "--version"); err == nil {
+				component.Version = versionPattern.FindString(out)
+			}
+		}
+		components = append(components, component)
+	}
+	return components
+}
+
+// pluginComponents 读取 compose、buildx 插件版本
+func (d *DockerManager) pluginComponents(ctx context.Context, pluginDir string) []soft.Component {
+	var components []soft.Component
+	for _, name := range []string{"docker-compose", "docker-buildx"} {
+		pluginFile := filepath.Join(pluginDir, name)
+		component := soft.Component{Name: name, State: "missing", Path: pluginFile}
+		if utils.PathExists(pluginFile) {
+			component.State = "present"
+			if out, err := utils.CommandOutput(ctx, pluginFile, "version"); err == nil {
+				component.Version = versionPattern.FindString(out)
+			}
+		}
+		components = append(components, component)
+	}
+	return components
+}
+
+// showVersionDiff 打印当前版本与目标版本的对比，返回是否存在差异
+func (d *DockerManager) showVersionDiff(ctx context.Context, ui ui.UI, cfg *config.DockerConfig, global *config.CommonConfig, target string, plugins []pluginArtifact) bool {
+	installPath := d.resolveInstallPath(cfg, global)
+	current := map[string]string{}
+	for _, component := range d.pluginComponents(ctx, filepath.Join(installPath, "plugins")) {
+		if component.State == "present" {
+			current[component.Name] = component.Version
+		}
+	}
+
+	changed := false
+	ui.Println("%-16s %-12s %-12s", "COMPONENT", "CURRENT", "TARGET")
+	row := func(name, from, to string) {
+		mark := ""
+		if !version.Same(from, to) {
+			changed = true
+			mark = " *"
+		}
+		ui.Println("%-16s %-12s %-12s%s", name, orDash(from), orDash(to), mark)
+	}
+
+	row("docker", d.installedVersion(ctx, filepath.Join(installPath, "bin")), target)
+	for _, plugin := range plugins {
+		from := current[plugin.Name]
+		if _, ok := current[plugin.Name]; ok && from == "" {
+			from = "unknown"
+		}
+		row(plugin.Name, from, plugin.Version)
+		delete(current, plugin.Name)
+	}
+	// 不再配置的插件会随插件目录一起被替换
+	for name, from := range current {
+		row(name, orDash(from), "")
+	}
+	return changed
+}
+
+// orDash 空值显示为 -
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// serviceComponent 查询 docker 服务的运行及开机自启状态
+func (d *DockerManager) serviceComponent(ctx context.Context, m installMode) soft.Component {
+	return soft.Component{
+		Name:  fmt.Sprintf("docker (%s)", m.Service.Name()),
+		Path:  m.Service.File(m.Spec),
+		State: service.State(ctx, m.Service, m.Spec),
+	}
 }
 
 // 合并JSON并写入文件
@@ -107,18 +300,5 @@ func (d *DockerManager) mergeJSONToFile(
 	}
 
 	// 写入文件
-	writeCmd := fmt.Sprintf("cat <<'EOF' | %s tee %s > /dev/null\n%s\nEOF",
-		func() string {
-			if useSudo {
-				return "sudo"
-			}
-			return ""
-		}(),
-		file, jsonStr,
-	)
-
-	// 去掉多余空格（当 useSudo = false 时）
-	writeCmd = strings.ReplaceAll(writeCmd, "|  tee", "| tee")
-
-	return utils.RunCommand(ctx, ui, env, "bash", "-c", writeCmd)
+	return utils.TeeFile(ctx, ui, env, file, jsonStr, useSudo)
 }
@@ -0,0 +1,92 @@
+package k3s
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/bookandmusic/dev-tools/internal/manager/soft"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft/staticbin"
+	"github.com/bookandmusic/dev-tools/internal/service"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+const (
+	k3sRepo = "k3s-io/k3s"
+	// kubeconfig k3s server 默认写入的 kubeconfig
+	kubeconfig = "/etc/rancher/k3s/k3s.yaml"
+)
+
+// buildSpec 生成安装描述，resolve 为 true 时解析 latest 版本（需要联网）
+func buildSpec(ctx context.Context, arch utils.ArchType, resolve bool) (*staticbin.Spec, error) {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return nil, err
+	}
+	cfg := params.Cfg
+	version := cfg.Version
+	if resolve {
+		v, err := staticbin.ResolveVersion(ctx, params.UI, params.Global, "k3s", k3sRepo, version)
+		if err != nil {
+			return nil, err
+		}
+		version = v
+	}
+	// k3s 的 tag 带 v 前缀，如 v1.30.5+k3s1
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+
+	file := "k3s"
+	if arch == utils.ArchAARCH64 {
+		file = "k3s-arm64"
+	}
+	installDir := utils.ExpandAbsDir(cfg.InstallDir)
+	spec := &staticbin.Spec{
+		Name:       "k3s",
+		Title:      "k3s",
+		Version:    version,
+		Arch:       arch,
+		InstallDir: installDir,
+		Artifacts: []staticbin.Artifact{{
+			Name:    "k3s",
+			Version: version,
+			File:    file,
+			// tag 中的 + 需要转义
+			URLs:   staticbin.GithubRelease(params.UI, params.Global, k3sRepo, strings.ReplaceAll(version, "+", "%2B"), file),
+			Binary: "k3s",
+		}},
+		ServiceManager: cfg.ServiceManager,
+		Env:            map[string]string{"KUBECONFIG": kubeconfig},
+		VersionArgs:    []string{"k3s", "--version"},
+	}
+	for _, name := range []string{"kubectl", "crictl", "ctr"} {
+		spec.Links = append(spec.Links, staticbin.Link{Name: filepath.Join("bin", name), Target: "k3s"})
+	}
+	spec.Services = []service.Spec{{
+		Name:        "k3s",
+		Description: "Lightweight Kubernetes",
+		Exec:        spec.BinPath("k3s"),
+		Args:        cfg.Args,
+		Env:         map[string]string{"PATH": filepath.Join(installDir, "bin")},
+		Notify:      true,
+		After:       []string{"network-online.target"},
+		Systemd: []string{
+			"ExecStartPre=-/sbin/modprobe br_netfilter",
+			"ExecStartPre=-/sbin/modprobe overlay",
+			"Delegate=yes",
+			"KillMode=process",
+			"LimitNOFILE=1048576",
+			"LimitNPROC=infinity",
+			"LimitCORE=infinity",
+			"TasksMax=infinity",
+			"TimeoutStartSec=0",
+		},
+	}}
+	return spec, nil
+}
+
+func init() {
+	// 安装单二进制的 k3s，并提供 kubectl、crictl、ctr 链接
+	soft.Register("k3s", staticbin.NewManager(buildSpec))
+}
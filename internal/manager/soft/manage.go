@@ -4,12 +4,63 @@ import (
 	"context"
 	"fmt"
 	"sync"
+
+	"github.com/bookandmusic/dev-tools/internal/bundle"
 )
 
 type SoftManage interface {
 	Install(ctx context.Context) error
 	Uninstall(ctx context.Context) error
 	Update(ctx context.Context) error
+	Status(ctx context.Context) (*Status, error)
+}
+
+// Component 软件的组成部分（二进制、插件、服务等）的状态
+type Component struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	State   string `json:"state,omitempty"`
+	Path    string `json:"path,omitempty"`
+}
+
+// Status 软件当前的安装状态，可直接序列化为 JSON 供脚本使用
+type Status struct {
+	Name       string      `json:"name"`
+	Installed  bool        `json:"installed"`
+	Version    string      `json:"version,omitempty"`
+	Path       string      `json:"path,omitempty"`
+	Components []Component `json:"components,omitempty"`
+}
+
+// Action 同步时对某个软件采取的动作
+type Action string
+
+const (
+	ActionInstall Action = "install"
+	ActionUpdate  Action = "update"
+	ActionSkip    Action = "skip"
+)
+
+// Reconciler 可选接口：对比机器当前状态与配置，给出 sync 需要执行的动作及原因
+type Reconciler interface {
+	Plan(ctx context.Context) (Action, string, error)
+}
+
+// Rollbacker 可选接口：撤销上一次安装对系统做的修改
+type Rollbacker interface {
+	Rollback(ctx context.Context) error
+}
+
+// Configurer 可选接口：查看、对比并应用软件自身的配置文件
+type Configurer interface {
+	ShowConfig(ctx context.Context) error
+	DiffConfig(ctx context.Context) error
+	ApplyConfig(ctx context.Context) error
+}
+
+// Bundler 可选接口：把安装所需的产物写入离线包，供 --bundle 离线安装
+type Bundler interface {
+	Bundle(ctx context.Context, w *bundle.Writer) error
 }
 
 var (
@@ -9,12 +9,16 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/bookandmusic/dev-tools/internal/bundle"
 	"github.com/bookandmusic/dev-tools/internal/config"
 	"github.com/bookandmusic/dev-tools/internal/manager/soft"
 	"github.com/bookandmusic/dev-tools/internal/ui"
 	"github.com/bookandmusic/dev-tools/internal/utils"
 )
 
+// ohmyzshRepo OhMyZsh 仓库地址
+const ohmyzshRepo = "https://github.com/ohmyzsh/ohmyzsh.git"
+
 // OhMyzshManager 实现OhMyZsh管理
 type OhMyzshManager struct{}
 
@@ -30,6 +34,10 @@ func (o *OhMyzshManager) installDependencies(ctx context.Context, ui ui.UI, cfg
 		return nil
 	}
 
+	if utils.IsOffline(ctx) {
+		return fmt.Errorf("离线模式下无法安装 zsh，请先通过系统包管理器安装: %w", utils.ErrOffline)
+	}
+
 	ui.Info("安装zsh和依赖...")
 	switch {
 	case utils.PathExists("/etc/debian_version"):
@@ -67,10 +75,17 @@ func (o *OhMyzshManager) setDefaultShell(ctx context.Context, ui ui.UI, cfg *con
 // cloneRepository 克隆OhMyZsh仓库
 func (o *OhMyzshManager) cloneRepository(ctx context.Context, ui ui.UI, cfg *config.OhMyzshConfig, global *config.CommonConfig, env map[string]string) error {
 	path := cfg.InstallDir
+	if b := bundle.FromContext(ctx); b != nil {
+		bundleFile, err := b.Path("ohmyzsh")
+		if err != nil {
+			return err
+		}
+		return utils.CloneFromBundle(ctx, ui, bundleFile, path, ohmyzshRepo, env)
+	}
 	return utils.CloneRepoWithProxy(
 		ctx,
 		ui,
-		"https://github.com/ohmyzsh/ohmyzsh.git",
+		ohmyzshRepo,
 		path, global.HttpProxy, global.GithubProxy, env,
 	)
 }
@@ -86,13 +101,13 @@ func (o *OhMyzshManager) setupZshrc(ctx context.Context, ui ui.UI, cfg *config.O
 
 	templatePath := filepath.Join(cfg.InstallDir, "templates", "zshrc.zsh-template")
 	ui.Info("生成.zshrc配置文件")
-	return utils.CopyFile(templatePath, zshrcPath)
+	return utils.CopyFile(ctx, templatePath, zshrcPath)
 }
 
 // installPlugins 安装所有插件
 func (o *OhMyzshManager) installPlugins(ctx context.Context, ui ui.UI, cfg *config.OhMyzshConfig, global *config.CommonConfig, env map[string]string) error {
 	pluginsDir := filepath.Join(cfg.InstallDir, "custom", "plugins")
-	if err := os.MkdirAll(pluginsDir, 0o700); err != nil {
+	if err := utils.MkdirAll(ctx, pluginsDir, 0o700); err != nil {
 		return err
 	}
 
@@ -101,6 +116,16 @@ func (o *OhMyzshManager) installPlugins(ctx context.Context, ui ui.UI, cfg *conf
 		pluginName := filepath.Base(plugin.Name)
 		pluginPath := filepath.Join(pluginsDir, pluginName)
 		url := fmt.Sprintf("https://github.com/%s.git", plugin.Repo)
+		if b := bundle.FromContext(ctx); b != nil {
+			bundleFile, err := b.Path(pluginBundleName(plugin.Name))
+			if err != nil {
+				return err
+			}
+			if err := utils.CloneFromBundle(ctx, ui, bundleFile, pluginPath, url, env); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := utils.CloneRepoWithProxy(
 			ctx, ui, url, pluginPath, global.HttpProxy, global.GithubProxy, env,
 		); err != nil {
@@ -115,7 +140,12 @@ func (o *OhMyzshManager) configureZshrc(ctx context.Context, ui ui.UI, cfg *conf
 	zshrcPath := filepath.Join(os.Getenv("HOME"), ".zshrc")
 	content, err := os.ReadFile(zshrcPath)
 	if err != nil {
-		return err
+		// 干跑模式下 .zshrc 可能尚未生成
+		if utils.DryRunFromContext(ctx) != nil && os.IsNotExist(err) {
+			content = nil
+		} else {
+			return err
+		}
 	}
 	lines := strings.Split(string(content), "\n")
 
@@ -163,7 +193,7 @@ func (o *OhMyzshManager) configureZshrc(ctx context.Context, ui ui.UI, cfg *conf
 	}
 
 	newContent := strings.Join(lines, "\n")
-	if err := os.WriteFile(zshrcPath, []byte(newContent), 0o600); err != nil {
+	if err := utils.WriteFile(ctx, zshrcPath, []byte(newContent), 0o600); err != nil {
 		return err
 	}
 
@@ -181,6 +211,14 @@ func (o *OhMyzshManager) Install(ctx context.Context) error {
 	console := params.UI
 	console.Info("开始安装Oh My Zsh...")
 
+	b, err := bundle.Open(console, params.Global.Bundle, params.Global.CacheDir)
+	if err != nil {
+		return err
+	}
+	if b != nil {
+		ctx = bundle.WithBundle(ctx, b)
+	}
+
 	steps := []func(context.Context, ui.UI, *config.OhMyzshConfig, *config.CommonConfig, map[string]string) error{
 		o.installDependencies,
 		o.setDefaultShell,
@@ -203,6 +241,71 @@ func (o *OhMyzshManager) Install(ctx context.Context) error {
 	return nil
 }
 
+// Plan 检查仓库与插件是否齐全，供 sync 使用
+func (o *OhMyzshManager) Plan(ctx context.Context) (soft.Action, string, error) {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return "", "", err
+	}
+	cfg := params.Cfg
+
+	if !utils.PathExists(filepath.Join(cfg.InstallDir, ".git")) {
+		return soft.ActionInstall, "oh-my-zsh not installed", nil
+	}
+
+	var missing []string
+	pluginsDir := filepath.Join(cfg.InstallDir, "custom", "plugins")
+	for _, plugin := range cfg.Plugins {
+		if !utils.PathExists(filepath.Join(pluginsDir, filepath.Base(plugin.Name), ".git")) {
+			missing = append(missing, plugin.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return soft.ActionInstall, fmt.Sprintf("missing plugins: %s", strings.Join(missing, ", ")), nil
+	}
+	return soft.ActionSkip, "oh-my-zsh and plugins installed", nil
+}
+
+// Status 报告仓库 HEAD 以及每个插件的提交
+func (o *OhMyzshManager) Status(ctx context.Context) (*soft.Status, error) {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return nil, err
+	}
+	cfg := params.Cfg
+
+	status := &soft.Status{Name: "ohmyzsh", Path: cfg.InstallDir}
+	status.Version = o.repoHead(ctx, cfg.InstallDir)
+	status.Installed = status.Version != ""
+	if !status.Installed {
+		return status, nil
+	}
+
+	pluginsDir := filepath.Join(cfg.InstallDir, "custom", "plugins")
+	for _, plugin := range cfg.Plugins {
+		pluginPath := filepath.Join(pluginsDir, filepath.Base(plugin.Name))
+		component := soft.Component{Name: plugin.Name, State: "missing", Path: pluginPath}
+		if head := o.repoHead(ctx, pluginPath); head != "" {
+			component.State = "present"
+			component.Version = head
+		}
+		status.Components = append(status.Components, component)
+	}
+	return status, nil
+}
+
+// repoHead 返回 git 仓库当前提交的短哈希，非仓库时返回空字符串
+func (o *OhMyzshManager) repoHead(ctx context.Context, repoPath string) string {
+	if !utils.PathExists(filepath.Join(repoPath, ".git")) {
+		return ""
+	}
+	head, err := utils.CommandOutput(ctx, "git", "-C", repoPath, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return head
+}
+
 // Update 实现SoftManage接口的更新方法
 func (o *OhMyzshManager) Update(ctx context.Context) error {
 	params, err := soft.Parse[BaseParams](ctx)
@@ -212,8 +315,25 @@ func (o *OhMyzshManager) Update(ctx context.Context) error {
 	cfg := params.Cfg
 	ui := params.UI
 	env := params.Env
+
+	b, err := bundle.Open(ui, params.Global.Bundle, params.Global.CacheDir)
+	if err != nil {
+		return err
+	}
+	// updateRepo 有离线包时从包内 bundle 快进，否则在线拉取
+	updateRepo := func(repoPath, name string) error {
+		if b == nil {
+			return utils.UpdateRepo(ctx, ui, repoPath, env, params.Global.HttpProxy)
+		}
+		bundleFile, err := b.Path(name)
+		if err != nil {
+			return err
+		}
+		return utils.UpdateRepoFromBundle(ctx, ui, repoPath, bundleFile, env)
+	}
+
 	ui.Info("更新Oh My Zsh...")
-	if err := utils.UpdateRepo(ctx, ui, cfg.InstallDir, env, params.Global.HttpProxy); err != nil {
+	if err := updateRepo(cfg.InstallDir, "ohmyzsh"); err != nil {
 		return err
 	}
 
@@ -222,7 +342,7 @@ func (o *OhMyzshManager) Update(ctx context.Context) error {
 	for _, plugin := range cfg.Plugins {
 		pluginName := filepath.Base(plugin.Name)
 		pluginPath := filepath.Join(pluginsDir, pluginName)
-		if err := utils.UpdateRepo(ctx, ui, pluginPath, env, params.Global.HttpProxy); err != nil {
+		if err := updateRepo(pluginPath, pluginBundleName(plugin.Name)); err != nil {
 			return err
 		}
 	}
@@ -231,6 +351,48 @@ func (o *OhMyzshManager) Update(ctx context.Context) error {
 	return nil
 }
 
+// Bundle 将 OhMyZsh 仓库及配置的插件仓库打成 git bundle 写入离线包
+func (o *OhMyzshManager) Bundle(ctx context.Context, w *bundle.Writer) error {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return err
+	}
+	ui := params.UI
+	global := params.Global
+
+	tmpDir, err := os.MkdirTemp("", "dtl-ohmyzsh-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// addRepo 打包单个仓库并写入离线包
+	addRepo := func(name, repoURL string) error {
+		bundleFile := filepath.Join(tmpDir, filepath.Base(name)+".bundle")
+		if err := utils.BundleRepo(ctx, ui, repoURL, bundleFile, global.HttpProxy, global.GithubProxy, params.Env); err != nil {
+			return err
+		}
+		return w.AddFile(name, bundleFile, filepath.Join("ohmyzsh", name+".bundle"))
+	}
+
+	if err := addRepo("ohmyzsh", ohmyzshRepo); err != nil {
+		return err
+	}
+	for _, plugin := range params.Cfg.Plugins {
+		url := fmt.Sprintf("https://github.com/%s.git", plugin.Repo)
+		if err := addRepo(pluginBundleName(plugin.Name), url); err != nil {
+			return err
+		}
+	}
+	ui.Success("已加入 Oh My Zsh 及 %d 个插件", len(params.Cfg.Plugins))
+	return nil
+}
+
+// pluginBundleName 插件在离线包中的名称
+func pluginBundleName(name string) string {
+	return "ohmyzsh-plugin/" + filepath.Base(name)
+}
+
 // Uninstall 实现SoftManage接口的卸载方法
 func (o *OhMyzshManager) Uninstall(ctx context.Context) error {
 	params, err := soft.Parse[BaseParams](ctx)
@@ -243,7 +405,7 @@ func (o *OhMyzshManager) Uninstall(ctx context.Context) error {
 
 	if utils.PathExists(cfg.InstallDir) {
 		ui.Info("删除目录: %s", cfg.InstallDir)
-		if err := os.RemoveAll(cfg.InstallDir); err != nil {
+		if err := utils.RemoveAll(ctx, cfg.InstallDir); err != nil {
 			return err
 		}
 	}
@@ -251,7 +413,7 @@ func (o *OhMyzshManager) Uninstall(ctx context.Context) error {
 	zshrcPath := filepath.Join(os.Getenv("HOME"), ".zshrc")
 	if utils.PathExists(zshrcPath) {
 		ui.Info("备份.zshrc: %s -> %s.bak", zshrcPath, zshrcPath)
-		if err := os.Rename(zshrcPath, zshrcPath+".bak"); err != nil {
+		if err := utils.Rename(ctx, zshrcPath, zshrcPath+".bak"); err != nil {
 			ui.Warning("备份.zshrc失败: %v", err)
 		}
 	}
@@ -0,0 +1,88 @@
+package podman
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bookandmusic/dev-tools/internal/manager/soft"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft/staticbin"
+	"github.com/bookandmusic/dev-tools/internal/service"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// podmanRepo 提供 podman 静态构建的仓库，包内附带 crun、conmon、netavark 等依赖
+const podmanRepo = "mgoltzsche/podman-static"
+
+// buildSpec 生成安装描述，resolve 为 true 时解析 latest 版本（需要联网）
+func buildSpec(ctx context.Context, arch utils.ArchType, resolve bool) (*staticbin.Spec, error) {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return nil, err
+	}
+	cfg := params.Cfg
+	version := cfg.Version
+	if resolve {
+		v, err := staticbin.ResolveVersion(ctx, params.UI, params.Global, "podman", podmanRepo, version)
+		if err != nil {
+			return nil, err
+		}
+		version = strings.TrimPrefix(v, "v")
+	}
+
+	installDir := utils.ExpandAbsDir(cfg.InstallDir)
+	file := fmt.Sprintf("podman-linux-%s.tar.gz", staticbin.GoArch(arch))
+	spec := &staticbin.Spec{
+		Name:       "podman",
+		Title:      "Podman",
+		Version:    version,
+		Arch:       arch,
+		InstallDir: installDir,
+		// 包内为 podman-linux-<arch>/usr/local/{bin,lib,libexec} 与 etc/containers
+		BinDir: "usr/local/bin",
+		Artifacts: []staticbin.Artifact{{
+			Name:    "podman",
+			Version: version,
+			File:    file,
+			URLs:    staticbin.GithubRelease(params.UI, params.Global, podmanRepo, "v"+version, file),
+			Strip:   1,
+			Dir:     ".",
+		}},
+		ServiceManager: cfg.ServiceManager,
+		VersionArgs:    []string{"podman", "--version"},
+	}
+
+	// 辅助程序不在默认搜索路径中，需要在 containers.conf 中指明
+	spec.Files = []staticbin.File{
+		{Path: "/etc/containers/containers.conf", Content: fmt.Sprintf(`[engine]
+helper_binaries_dir = [%q, %q]
+
+[engine.runtimes]
+crun = [%q]
+runc = [%q]`,
+			filepath.Join(installDir, "usr/local/lib/podman"),
+			filepath.Join(installDir, "usr/local/libexec/podman"),
+			spec.BinPath("crun"),
+			spec.BinPath("runc"),
+		)},
+		{Path: "/etc/containers/policy.json", Content: `{"default": [{"type": "insecureAcceptAnything"}]}`},
+		{Path: "/etc/containers/registries.conf", Content: `unqualified-search-registries = ["docker.io"]`},
+	}
+	if cfg.Service {
+		spec.Services = []service.Spec{{
+			Name:        "podman",
+			Description: "Podman API Service",
+			Exec:        spec.BinPath("podman"),
+			Args:        []string{"system", "service", "--time=0", "unix:///run/podman/podman.sock"},
+			Env:         map[string]string{"PATH": filepath.Join(installDir, "usr/local/bin")},
+			Systemd:     []string{"Delegate=true", "KillMode=process"},
+		}}
+	}
+	return spec, nil
+}
+
+func init() {
+	// 安装 podman 静态构建
+	soft.Register("podman", staticbin.NewManager(buildSpec))
+}
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	yaml "gopkg.in/yaml.v3"
 
+	"github.com/bookandmusic/dev-tools/internal/bundle"
 	"github.com/bookandmusic/dev-tools/internal/config"
 	"github.com/bookandmusic/dev-tools/internal/manager/soft"
 	"github.com/bookandmusic/dev-tools/internal/ui"
@@ -30,39 +32,47 @@ func (s *SelfManager) Install(ctx context.Context) error {
 
 	ui.Info("Installing dev-tools to: %s", rootAbsDir)
 
+	b, err := bundle.Open(ui, cfg.Common.Bundle, cfg.Common.CacheDir)
+	if err != nil {
+		return err
+	}
+	if b != nil {
+		ctx = bundle.WithBundle(ctx, b)
+	}
+
 	// 1️⃣ 创建安装目录
-	if err := s.createInstallDirs(binDir, pluginDir); err != nil {
+	if err := s.createInstallDirs(ctx, binDir, pluginDir); err != nil {
 		return err
 	}
 
 	// 2️⃣ 复制当前可执行文件到 bin
-	if err := s.copyExecutable(binDir, ui); err != nil {
+	if err := s.copyExecutable(ctx, binDir, ui); err != nil {
 		return err
 	}
 
 	// 3️⃣ 复制 plugins（如果有）
-	if err := s.copyPlugins(cfg.Common.WorkDir, pluginDir, ui); err != nil {
+	if err := s.copyPlugins(ctx, cfg.Common.WorkDir, pluginDir, ui); err != nil {
 		return err
 	}
 
 	// 4️⃣ 生成默认配置
-	if err := s.generateConfig(rootAbsDir, cfg); err != nil {
+	if err := s.generateConfig(ctx, rootAbsDir, cfg); err != nil {
 		return err
 	}
 
 	// 5️⃣ 生成 .env
-	if err := s.generateEnvFile(rootAbsDir, binDir, ui); err != nil {
+	if err := s.generateEnvFile(ctx, rootAbsDir, binDir, ui); err != nil {
 		return err
 	}
 
 	// 6️⃣ 生成加载脚本 load_dtl.sh
-	loadScriptPath, err := s.generateLoadScript(rootAbsDir, ui)
+	loadScriptPath, err := s.generateLoadScript(ctx, rootAbsDir, ui)
 	if err != nil {
 		return err
 	}
 
 	// 7️⃣ 链接到用户 shell 配置文件
-	if err := s.linkToShellProfile(loadScriptPath, ui); err != nil {
+	if err := s.linkToShellProfile(ctx, loadScriptPath, ui); err != nil {
 		return err
 	}
 
@@ -70,37 +80,45 @@ func (s *SelfManager) Install(ctx context.Context) error {
 	return nil
 }
 
-func (s *SelfManager) createInstallDirs(binDir, pluginDir string) error {
-	if err := os.MkdirAll(binDir, 0o700); err != nil {
+func (s *SelfManager) createInstallDirs(ctx context.Context, binDir, pluginDir string) error {
+	if err := utils.MkdirAll(ctx, binDir, 0o700); err != nil {
 		return fmt.Errorf("failed to create bin dir: %w", err)
 	}
-	if err := os.MkdirAll(pluginDir, 0o700); err != nil {
+	if err := utils.MkdirAll(ctx, pluginDir, 0o700); err != nil {
 		return fmt.Errorf("failed to create plugins dir: %w", err)
 	}
 	return nil
 }
 
-func (s *SelfManager) copyExecutable(binDir string, ui ui.UI) error {
+func (s *SelfManager) copyExecutable(ctx context.Context, binDir string, ui ui.UI) error {
 	execPath, err := os.Executable()
 	if err != nil {
 		return fmt.Errorf("failed to get exec path: %w", err)
 	}
+	if b := bundle.FromContext(ctx); b != nil {
+		if execPath, err = b.Path("dtl"); err != nil {
+			return err
+		}
+	}
 	targetPath := filepath.Join(binDir, "dtl")
-	if err := utils.CopyFile(execPath, targetPath); err != nil {
+	if err := utils.CopyFile(ctx, execPath, targetPath); err != nil {
 		return fmt.Errorf("failed to copy exec: %w", err)
 	}
 	ui.Info("Copied executable to: %s", targetPath)
 	return nil
 }
 
-func (s *SelfManager) copyPlugins(workDir, pluginDir string, ui ui.UI) error {
+func (s *SelfManager) copyPlugins(ctx context.Context, workDir, pluginDir string, ui ui.UI) error {
 	if workDir == "" || !utils.PathExists(workDir) {
 		execPath, _ := os.Executable()
 		workDir = filepath.Dir(execPath)
 	}
 	srcPlugins := filepath.Join(workDir, "plugins")
+	if b := bundle.FromContext(ctx); b != nil && b.Has("plugins") {
+		srcPlugins, _ = b.Path("plugins")
+	}
 	if info, err := os.Stat(srcPlugins); err == nil && info.IsDir() {
-		if err := utils.CopyDirWithProgress(srcPlugins, pluginDir, ui); err != nil {
+		if err := utils.CopyDirWithProgress(ctx, srcPlugins, pluginDir, ui); err != nil {
 			return fmt.Errorf("failed to copy plugins: %w", err)
 		}
 	} else {
@@ -109,21 +127,21 @@ func (s *SelfManager) copyPlugins(workDir, pluginDir string, ui ui.UI) error {
 	return nil
 }
 
-func (s *SelfManager) generateConfig(rootAbsDir string, cfg *config.GlobalConfig) error {
+func (s *SelfManager) generateConfig(ctx context.Context, rootAbsDir string, cfg *config.GlobalConfig) error {
 	cfg.Common.WorkDir = cfg.Common.RootDir
 	configFile := filepath.Join(rootAbsDir, "config.yml")
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
 		data, _ := yaml.Marshal(cfg)
-		if err := os.WriteFile(configFile, data, 0o600); err != nil {
+		if err := utils.WriteFile(ctx, configFile, data, 0o600); err != nil {
 			return fmt.Errorf("failed to create config file: %w", err)
 		}
 	}
 	return nil
 }
 
-func (s *SelfManager) generateEnvFile(rootAbsDir, binDir string, ui ui.UI) error {
+func (s *SelfManager) generateEnvFile(ctx context.Context, rootAbsDir, binDir string, ui ui.UI) error {
 	envFile := filepath.Join(rootAbsDir, ".env")
-	err := utils.UpdateEnvFile(envFile, map[string]string{
+	err := utils.UpdateEnvFile(ctx, envFile, map[string]string{
 		"PATH":           binDir,
 		"DEV_TOOLS_HOME": rootAbsDir,
 	}, "add")
@@ -135,7 +153,7 @@ func (s *SelfManager) generateEnvFile(rootAbsDir, binDir string, ui ui.UI) error
 	return nil
 }
 
-func (s *SelfManager) generateLoadScript(rootAbsDir string, ui ui.UI) (string, error) {
+func (s *SelfManager) generateLoadScript(ctx context.Context, rootAbsDir string, ui ui.UI) (string, error) {
 	envFile := filepath.Join(rootAbsDir, ".env")
 	loadScriptPath := filepath.Join(rootAbsDir, "load_dtl.sh")
 	loadScript := fmt.Sprintf(`#!/bin/sh
@@ -147,19 +165,18 @@ if [ -f "$ENV_FILE" ]; then
     set +a
 fi
 `, envFile)
-	if err := os.WriteFile(loadScriptPath, []byte(loadScript), 0o600); err != nil {
+	if err := utils.WriteFile(ctx, loadScriptPath, []byte(loadScript), 0o700); err != nil {
 		return "", fmt.Errorf("failed to write loader: %w", err)
 	}
-
-	if err := os.Chmod(loadScriptPath, 0o700); err != nil {
-		return "", fmt.Errorf("failed to chmod loader: %w", err)
-	}
 	ui.Info("Generated load script: %s", loadScriptPath)
 	return loadScriptPath, nil
 }
 
-func (s *SelfManager) linkToShellProfile(loadScriptPath string, ui ui.UI) error {
-	currentUser, _ := utils.GetCurrentUser()
+func (s *SelfManager) linkToShellProfile(ctx context.Context, loadScriptPath string, ui ui.UI) error {
+	currentUser, err := utils.GetCurrentUser()
+	if err != nil {
+		return fmt.Errorf("failed to get current user: %w", err)
+	}
 	homeDir, shell, _ := utils.GetUserHomeAndShell(currentUser.Username)
 	profilePath := utils.GetProfilePath(shell, homeDir)
 
@@ -168,13 +185,49 @@ func (s *SelfManager) linkToShellProfile(loadScriptPath string, ui ui.UI) error
 [ -s "%s" ] && . "%s"
 # <<< dev-tools <<<`, loadScriptPath, loadScriptPath)
 
-	if err := utils.EnsureBlockInFile(profilePath, "# >>> dev-tools >>>", "# <<< dev-tools <<<", block); err != nil {
+	if err := utils.EnsureBlockInFile(ctx, profilePath, "# >>> dev-tools >>>", "# <<< dev-tools <<<", block); err != nil {
 		return fmt.Errorf("failed to update %s: %w", profilePath, err)
 	}
 	ui.Info("Linked loader into: %s", profilePath)
 	return nil
 }
 
+// Bundle 将当前可执行文件和 plugins 目录写入离线包
+func (s *SelfManager) Bundle(ctx context.Context, w *bundle.Writer) error {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return err
+	}
+	ui := params.UI
+
+	// 离线包中的 dtl 就是当前运行的可执行文件，只能用于相同架构
+	if arch := utils.DetectArch(); w.Arch() != string(arch) {
+		return fmt.Errorf("cannot bundle dtl for %s from a %s binary", w.Arch(), arch)
+	}
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get exec path: %w", err)
+	}
+	if err := w.AddFile("dtl", execPath, "self/dtl"); err != nil {
+		return err
+	}
+
+	workDir := params.Cfg.Common.WorkDir
+	if workDir == "" || !utils.PathExists(workDir) {
+		workDir = filepath.Dir(execPath)
+	}
+	srcPlugins := filepath.Join(workDir, "plugins")
+	if info, err := os.Stat(srcPlugins); err == nil && info.IsDir() {
+		if err := w.AddDir("plugins", srcPlugins, "self/plugins"); err != nil {
+			return err
+		}
+		ui.Success("Added dtl and plugins")
+	} else {
+		ui.Warning("No plugins directory found, only dtl is bundled")
+	}
+	return nil
+}
+
 func (s *SelfManager) Uninstall(ctx context.Context) error {
 	params, err := soft.Parse[BaseParams](ctx)
 	if err != nil {
@@ -188,27 +241,80 @@ func (s *SelfManager) Uninstall(ctx context.Context) error {
 
 	// 删除安装目录
 	if utils.PathExists(rootAbsDir) {
-		if err := os.RemoveAll(rootAbsDir); err != nil {
+		if err := utils.RemoveAll(ctx, rootAbsDir); err != nil {
 			return fmt.Errorf("failed to remove %s: %w", rootAbsDir, err)
 		}
 		ui.Info("Removed: %s", rootAbsDir)
 	}
 
 	// 删除 shell 配置文件里的加载入口
-	currentUser, _ := utils.GetCurrentUser()
-	homeDir, shell, _ := utils.GetUserHomeAndShell(currentUser.Username)
-	profilePath := utils.GetProfilePath(shell, homeDir)
-	// 从 profile 移除 block
-	if err := utils.RemoveLinesInFile(profilePath, "# >>> dev-tools >>>", "# <<< dev-tools <<<"); err != nil {
+	if currentUser, err := utils.GetCurrentUser(); err != nil {
 		ui.Warning("Failed to clean profile: %s", err)
 	} else {
-		ui.Info("Cleaned dev-tools environment block from: %s", profilePath)
+		homeDir, shell, _ := utils.GetUserHomeAndShell(currentUser.Username)
+		profilePath := utils.GetProfilePath(shell, homeDir)
+		// 从 profile 移除 block
+		if err := utils.RemoveLinesInFile(ctx, profilePath, "# >>> dev-tools >>>", "# <<< dev-tools <<<"); err != nil {
+			ui.Warning("Failed to clean profile: %s", err)
+		} else {
+			ui.Info("Cleaned dev-tools environment block from: %s", profilePath)
+		}
 	}
 
 	ui.Success("Uninstall complete.")
 	return nil
 }
 
+// Status 报告可执行文件、配置、环境文件、插件目录以及 shell 配置的接入情况
+func (s *SelfManager) Status(ctx context.Context) (*soft.Status, error) {
+	params, err := soft.Parse[BaseParams](ctx)
+	if err != nil {
+		return nil, err
+	}
+	rootAbsDir := utils.ExpandAbsDir(params.Cfg.Common.RootDir)
+	binFile := filepath.Join(rootAbsDir, "bin", "dtl")
+
+	status := &soft.Status{Name: "self", Path: rootAbsDir, Installed: utils.PathExists(binFile)}
+	if !status.Installed {
+		return status, nil
+	}
+
+	for _, item := range []struct{ name, path string }{
+		{"dtl", binFile},
+		{"config.yml", filepath.Join(rootAbsDir, "config.yml")},
+		{".env", filepath.Join(rootAbsDir, ".env")},
+		{"load_dtl.sh", filepath.Join(rootAbsDir, "load_dtl.sh")},
+	} {
+		state := "missing"
+		if utils.PathExists(item.path) {
+			state = "present"
+		}
+		status.Components = append(status.Components, soft.Component{Name: item.name, State: state, Path: item.path})
+	}
+
+	pluginDir := filepath.Join(rootAbsDir, "plugins")
+	if params.CountPlugins != nil && utils.PathExists(pluginDir) {
+		status.Components = append(status.Components, soft.Component{
+			Name: "plugins", State: fmt.Sprintf("%d installed", params.CountPlugins(pluginDir)), Path: pluginDir,
+		})
+	}
+
+	// 无法确定当前用户时 shell 配置文件未知，只报告状态未知
+	currentUser, err := utils.GetCurrentUser()
+	if err != nil {
+		status.Components = append(status.Components, soft.Component{Name: "profile", State: "unknown"})
+		return status, nil
+	}
+	homeDir, shell, _ := utils.GetUserHomeAndShell(currentUser.Username)
+	profilePath := utils.GetProfilePath(shell, homeDir)
+	state := "not linked"
+	if content, err := os.ReadFile(profilePath); err == nil && strings.Contains(string(content), "# >>> dev-tools >>>") {
+		state = "linked"
+	}
+	status.Components = append(status.Components, soft.Component{Name: "profile", State: state, Path: profilePath})
+	return status, nil
+}
+
 func (s *SelfManager) Update(ctx context.Context) error {
 	params, err := soft.Parse[BaseParams](ctx)
 	if err != nil {
@@ -9,4 +9,6 @@ type BaseParams struct {
 	UI  ui.UI                `ctx:"ui"`
 	Cfg *config.GlobalConfig `ctx:"cfg"`
 	Env map[string]string    `ctx:"env"`
+	// CountPlugins 统计插件目录中可加载的插件数，由命令层注入
+	CountPlugins func(pluginDir string) int `ctx:"count-plugins"`
 }
@@ -0,0 +1,398 @@
+package staticbin
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+
+	"github.com/bookandmusic/dev-tools/internal/bundle"
+	"github.com/bookandmusic/dev-tools/internal/cache"
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/lock"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft"
+	"github.com/bookandmusic/dev-tools/internal/service"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+	"github.com/bookandmusic/dev-tools/internal/version"
+)
+
+// Installer 按 Spec 执行静态二进制服务的通用流程：
+// 下载到缓存 → 解压到安装目录 → 添加执行权限 → 写入配置与服务 → 启动 → 更新 .env 中的 PATH → 记录 lock
+type Installer struct {
+	UI     ui.UI
+	Env    map[string]string
+	Global *config.CommonConfig
+}
+
+// Install 安装或覆盖安装
+func (in *Installer) Install(ctx context.Context, spec *Spec) error {
+	ui := in.UI
+	ui.Info("准备安装 %s %s, 架构: %s", spec.Title, spec.Version, spec.Arch)
+
+	svc, err := service.New(spec.ServiceManager, false)
+	if err != nil {
+		return err
+	}
+	if len(spec.Services) > 0 || len(spec.Files) > 0 {
+		ui.Info("检测sudo权限")
+		if err := utils.RunCommand(ctx, ui, in.Env, "sudo", "-v"); err != nil {
+			ui.Error("获取sudo权限失败")
+			return err
+		}
+	}
+
+	// 先全部下载，下载失败时系统未被修改
+	files := make([]string, 0, len(spec.Artifacts))
+	for _, artifact := range spec.Artifacts {
+		file, err := in.Fetch(ctx, spec, artifact)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+
+	in.stopServices(ctx, svc, spec)
+
+	for i, artifact := range spec.Artifacts {
+		if err := in.place(ctx, spec, artifact, files[i]); err != nil {
+			return err
+		}
+	}
+	if err := ChmodExecutables(ctx, ui, in.Env, spec.binDir(), false); err != nil {
+		return err
+	}
+	for _, link := range spec.Links {
+		linkPath := filepath.Join(spec.InstallDir, link.Name)
+		if err := utils.RunCommand(ctx, ui, in.Env, "ln", "-sf", link.Target, linkPath); err != nil {
+			return err
+		}
+	}
+
+	if err := in.writeFiles(ctx, spec); err != nil {
+		return err
+	}
+	if err := in.startServices(ctx, svc, spec); err != nil {
+		return err
+	}
+
+	if err := in.updateEnv(ctx, spec, "add"); err != nil {
+		ui.Error("添加环境变量失败")
+		return err
+	}
+
+	record := lock.Record{Version: spec.Version, Arch: string(spec.Arch), Components: map[string]string{}}
+	for _, artifact := range spec.Artifacts[1:] {
+		record.Components[artifact.Name] = artifact.Version
+	}
+	if err := lock.Update(ctx, in.Global.RootDir, func(l *lock.Lock) { l.Set(spec.Name, record) }); err != nil {
+		ui.Warning("更新 lock 文件失败: %v", err)
+	}
+
+	ui.Success("成功安装 %s %s", spec.Title, spec.Version)
+	ui.Info("重新打开终端或运行 source %s 后生效", path.Join(in.Global.RootDir, ".env"))
+	return nil
+}
+
+// Update 已是目标版本时跳过，否则覆盖安装
+func (in *Installer) Update(ctx context.Context, spec *Spec) error {
+	current := in.InstalledVersion(ctx, spec)
+	if current != "" && version.Same(current, spec.Version) {
+		artifact, installed, ok := in.outdatedComponent(spec, version.Same)
+		if !ok {
+			in.UI.Success("%s 已是目标版本 %s，无需更新", spec.Title, current)
+			return nil
+		}
+		in.UI.Info("%s: %s -> %s", artifact.Name, orDash(installed), artifact.Version)
+		return in.Install(ctx, spec)
+	}
+	in.UI.Info("%s: %s -> %s", spec.Title, orDash(current), spec.Version)
+	return in.Install(ctx, spec)
+}
+
+// outdatedComponent 返回第一个 lock 中记录的版本不满足 match 的附带发布文件及其已安装版本，
+// lock 文件无法读取时视为无法判断
+func (in *Installer) outdatedComponent(spec *Spec, match func(want, installed string) bool) (Artifact, string, bool) {
+	if len(spec.Artifacts) < 2 {
+		return Artifact{}, "", false
+	}
+	l, err := lock.Open(in.Global.RootDir)
+	if err != nil {
+		return Artifact{}, "", false
+	}
+	record, _ := l.Get(spec.Name)
+	for _, artifact := range spec.Artifacts[1:] {
+		installed := record.Components[artifact.Name]
+		if installed == "" || !match(artifact.Version, installed) {
+			return artifact, installed, true
+		}
+	}
+	return Artifact{}, "", false
+}
+
+// Uninstall 停止并删除服务、配置文件、安装目录以及环境变量
+func (in *Installer) Uninstall(ctx context.Context, spec *Spec) error {
+	ui := in.UI
+	svc, err := service.New(spec.ServiceManager, false)
+	if err != nil {
+		return err
+	}
+
+	services := spec.services()
+	for i := len(services) - 1; i >= 0; i-- {
+		s := services[i]
+		ui.Info("停止并禁用 %s 服务...", s.Name)
+		for _, action := range []service.Action{service.Stop, service.Disable} {
+			_ = service.Run(ctx, ui, in.Env, svc, s, action)
+		}
+		_ = service.Remove(ctx, ui, in.Env, svc, s)
+	}
+	if len(services) > 0 {
+		_ = service.Run(ctx, ui, in.Env, svc, services[0], service.Refresh)
+	}
+
+	for _, file := range spec.Files {
+		if utils.PathExists(file.Path) {
+			ui.Info("删除配置文件: %s", file.Path)
+			_ = utils.RunCommand(ctx, ui, in.Env, "sudo", "rm", "-f", file.Path)
+		}
+	}
+	if utils.PathExists(spec.InstallDir) {
+		ui.Info("删除安装目录: %s", spec.InstallDir)
+		if err := utils.RemoveAll(ctx, spec.InstallDir); err != nil {
+			return err
+		}
+	}
+
+	if err := in.updateEnv(ctx, spec, "remove"); err != nil {
+		ui.Error("删除环境变量失败")
+		return err
+	}
+	if err := lock.Update(ctx, in.Global.RootDir, func(l *lock.Lock) { l.Delete(spec.Name) }); err != nil {
+		ui.Warning("更新 lock 文件失败: %v", err)
+	}
+	ui.Success("%s 卸载完成", spec.Title)
+	return nil
+}
+
+// Plan 对比已安装版本与目标版本，供 sync 使用
+func (in *Installer) Plan(ctx context.Context, spec *Spec) (soft.Action, string) {
+	current := in.InstalledVersion(ctx, spec)
+	switch {
+	case current == "":
+		return soft.ActionInstall, spec.Name + " not installed"
+	}
+	if artifact, installed, ok := in.outdatedComponent(spec, version.Match); ok {
+		return soft.ActionUpdate, fmt.Sprintf("%s component %s %s installed, want %s", spec.Name, artifact.Name, orDash(installed), orDash(artifact.Version))
+	}
+	switch {
+	case version.IsLatest(spec.Version):
+		return soft.ActionSkip, fmt.Sprintf("%s %s installed, version not pinned", spec.Name, current)
+	case !version.Match(spec.Version, current):
+		return soft.ActionUpdate, fmt.Sprintf("%s %s installed, want %s", spec.Name, current, spec.Version)
+	case !version.IsExact(spec.Version):
+		return soft.ActionSkip, fmt.Sprintf("%s %s installed, satisfies %s", spec.Name, current, spec.Version)
+	default:
+		return soft.ActionSkip, fmt.Sprintf("%s %s up to date", spec.Name, current)
+	}
+}
+
+// Status 报告主程序及各发布文件的版本以及服务状态
+func (in *Installer) Status(ctx context.Context, spec *Spec) *soft.Status {
+	status := &soft.Status{Name: spec.Name, Path: spec.InstallDir}
+	status.Version = in.InstalledVersion(ctx, spec)
+	status.Installed = status.Version != ""
+	if !status.Installed {
+		return status
+	}
+	// 组件版本以 lock 文件中记录的实际安装版本为准
+	var record lock.Record
+	if l, err := lock.Open(in.Global.RootDir); err == nil {
+		record, _ = l.Get(spec.Name)
+	}
+	for i, artifact := range spec.Artifacts {
+		component := soft.Component{Name: artifact.Name, Version: record.Components[artifact.Name], State: "installed"}
+		if i == 0 {
+			component.Version = status.Version
+		}
+		status.Components = append(status.Components, component)
+	}
+	if svc, err := service.New(spec.ServiceManager, false); err == nil {
+		for _, s := range spec.services() {
+			status.Components = append(status.Components, soft.Component{
+				Name:  fmt.Sprintf("%s (%s)", s.Name, svc.Name()),
+				Path:  svc.File(s),
+				State: service.State(ctx, svc, s),
+			})
+		}
+	}
+	return status
+}
+
+// Bundle 将所有发布文件写入离线包
+func (in *Installer) Bundle(ctx context.Context, w *bundle.Writer, spec *Spec) error {
+	w.SetVersion(spec.Name, spec.Version)
+	for _, artifact := range spec.Artifacts {
+		file, err := in.Fetch(ctx, spec, artifact)
+		if err != nil {
+			return err
+		}
+		w.SetVersion(artifact.Name, artifact.Version)
+		if err := w.AddFile(artifact.Name, file, path.Join(spec.Name, artifact.File)); err != nil {
+			return err
+		}
+	}
+	in.UI.Success("已加入 %s %s (%s)", spec.Title, spec.Version, spec.Arch)
+	return nil
+}
+
+// InstalledVersion 执行 VersionArgs 读取已安装版本，未安装时返回空字符串
+func (in *Installer) InstalledVersion(ctx context.Context, spec *Spec) string {
+	if len(spec.VersionArgs) == 0 {
+		return ""
+	}
+	bin := spec.BinPath(spec.VersionArgs[0])
+	if !utils.PathExists(bin) {
+		return ""
+	}
+	out, err := utils.CommandOutput(ctx, bin, spec.VersionArgs[1:]...)
+	if err != nil {
+		return ""
+	}
+	return parseVersion(out)
+}
+
+// Fetch 返回发布文件路径：使用离线包时直接取包内文件，否则按 checksum 校验后下载到缓存
+func (in *Installer) Fetch(ctx context.Context, spec *Spec, artifact Artifact) (string, error) {
+	if b := bundle.FromContext(ctx); b != nil {
+		return b.Path(artifact.Name)
+	}
+	ui := in.UI
+	cachePath := filepath.Join(in.Global.CacheDir, spec.Name, artifact.Version, string(spec.Arch), artifact.File)
+	checksum := artifact.SHA256
+	if checksum == "" && len(artifact.URLs) > 0 {
+		checksum = utils.PinnedChecksum(in.Global.Checksums, artifact.URLs[len(artifact.URLs)-1])
+	}
+	opts := in.Global.DownloadOptions(checksum)
+	if artifact.Direct {
+		opts.HttpProxy = ""
+	}
+	ui.Info("准备 %s %s: %s", artifact.Name, artifact.Version, cachePath)
+	if err := utils.DownloadToCache(ctx, ui, artifact.URLs, cachePath, opts); err != nil {
+		ui.Error("下载 %s 失败", artifact.Name)
+		return "", err
+	}
+	entry := cache.Entry{Name: artifact.Name, Version: artifact.Version, Arch: string(spec.Arch), SHA256: checksum, Path: cachePath}
+	if len(artifact.URLs) > 0 {
+		entry.URL = artifact.URLs[len(artifact.URLs)-1]
+	}
+	if err := cache.Track(ctx, in.Global.CacheDir, entry); err != nil {
+		ui.Warning("更新缓存索引失败: %v", err)
+	}
+	return cachePath, nil
+}
+
+// place 解压发布文件，单个可执行文件直接复制
+func (in *Installer) place(ctx context.Context, spec *Spec, artifact Artifact, file string) error {
+	dir := artifact.Dir
+	if dir == "" {
+		dir = "bin"
+	}
+	dest := filepath.Join(spec.InstallDir, dir)
+	if err := utils.MkdirAll(ctx, dest, 0o755); err != nil {
+		return err
+	}
+	if artifact.Binary != "" {
+		in.UI.Info("复制 %s 到 %s", artifact.Name, dest)
+		return utils.CopyFile(ctx, file, filepath.Join(dest, artifact.Binary))
+	}
+	in.UI.Info("解压文件:%s 到 %s", filepath.Base(file), dest)
+	if err := utils.ExtractTarGzWithProgress(ctx, in.UI, file, dest, artifact.Strip); err != nil {
+		in.UI.Error("解压 %s 失败", artifact.Name)
+		return err
+	}
+	return nil
+}
+
+// writeFiles 写入不存在的配置文件，已有文件保留用户的修改
+func (in *Installer) writeFiles(ctx context.Context, spec *Spec) error {
+	for _, file := range spec.Files {
+		if utils.PathExists(file.Path) {
+			in.UI.Info("%s 已存在，保留现有配置", file.Path)
+			continue
+		}
+		if err := utils.RunCommand(ctx, in.UI, in.Env, "sudo", "mkdir", "-p", filepath.Dir(file.Path)); err != nil {
+			return err
+		}
+		in.UI.Info("写入 %s", file.Path)
+		if err := utils.TeeFile(ctx, in.UI, in.Env, file.Path, file.Content, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stopServices 停止正在运行的服务，以便替换二进制
+func (in *Installer) stopServices(ctx context.Context, svc service.Manager, spec *Spec) {
+	services := spec.services()
+	for i := len(services) - 1; i >= 0; i-- {
+		s := services[i]
+		if svc.IsActive(ctx, s) {
+			in.UI.Info("停止正在运行的 %s 服务...", s.Name)
+			_ = service.Run(ctx, in.UI, in.Env, svc, s, service.Stop)
+		}
+	}
+}
+
+// startServices 写入服务定义并启动
+func (in *Installer) startServices(ctx context.Context, svc service.Manager, spec *Spec) error {
+	if len(spec.Services) == 0 {
+		return nil
+	}
+	in.UI.Info("服务管理方式: %s", svc.Name())
+	for _, s := range spec.services() {
+		if err := service.Install(ctx, in.UI, in.Env, svc, s); err != nil {
+			return err
+		}
+		in.UI.Info("启动 %s 服务...", s.Name)
+		if err := service.Run(ctx, in.UI, in.Env, svc, s, service.Refresh, service.Enable, service.Start); err != nil {
+			in.UI.Error("启动 %s 服务失败", s.Name)
+			return err
+		}
+	}
+	if svc.Name() == service.Supervised {
+		in.UI.Warning("当前系统没有可用的 init，%s 不会开机自启", spec.Title)
+	}
+	return nil
+}
+
+// updateEnv 在 RootDir/.env 中添加或删除 PATH 及额外的环境变量
+func (in *Installer) updateEnv(ctx context.Context, spec *Spec, mode string) error {
+	vars := map[string]string{"PATH": spec.binDir()}
+	for k, v := range spec.Env {
+		vars[k] = v
+	}
+	return utils.UpdateEnvFile(ctx, path.Join(in.Global.RootDir, ".env"), vars, mode)
+}
+
+// ChmodExecutables 为目录下的文件添加可执行权限
+func ChmodExecutables(ctx context.Context, ui ui.UI, env map[string]string, dir string, sudo bool) error {
+	ui.Info("添加可执行权限...")
+	args := []string{"bash", "-c", fmt.Sprintf("find %s -maxdepth 1 -type f -exec chmod +x {} \\;", dir)}
+	if sudo {
+		args = append([]string{"sudo"}, args...)
+	}
+	if err := utils.RunCommand(ctx, ui, env, args[0], args[1:]...); err != nil {
+		ui.Error("设置执行权限失败")
+		return err
+	}
+	return nil
+}
+
+// orDash 空值显示为 -
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
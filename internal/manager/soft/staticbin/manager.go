@@ -0,0 +1,129 @@
+package staticbin
+
+import (
+	"context"
+
+	"github.com/bookandmusic/dev-tools/internal/bundle"
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// Params 通用管理器从 context 中读取的参数，软件自身的配置由 SpecFunc 读取
+type Params struct {
+	UI     ui.UI                `ctx:"ui"`
+	Env    map[string]string    `ctx:"env"`
+	Global *config.CommonConfig `ctx:"global"`
+}
+
+// SpecFunc 按 context 中的配置生成安装描述，resolve 为 true 时解析 latest 版本（需要联网）
+type SpecFunc func(ctx context.Context, arch utils.ArchType, resolve bool) (*Spec, error)
+
+// Manager 基于 Installer 的通用 soft.SoftManage 实现，同时支持 sync 与离线包
+type Manager struct {
+	spec SpecFunc
+}
+
+// NewManager 返回按 spec 生成安装描述的管理器
+func NewManager(spec SpecFunc) *Manager {
+	return &Manager{spec: spec}
+}
+
+// installer 返回通用的静态二进制安装器
+func (m *Manager) installer(ctx context.Context) (*Installer, error) {
+	params, err := soft.Parse[Params](ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Installer{UI: params.UI, Env: params.Env, Global: params.Global}, nil
+}
+
+// prepare 打开离线包并按主机架构生成安装描述
+func (m *Manager) prepare(ctx context.Context) (context.Context, *Installer, *Spec, error) {
+	in, err := m.installer(ctx)
+	if err != nil {
+		return ctx, nil, nil, err
+	}
+	if ctx, err = WithBundle(ctx, in.UI, in.Global); err != nil {
+		return ctx, nil, nil, err
+	}
+	arch, err := HostArch(ctx)
+	if err != nil {
+		return ctx, nil, nil, err
+	}
+	spec, err := m.spec(ctx, arch, true)
+	if err != nil {
+		return ctx, nil, nil, err
+	}
+	return ctx, in, spec, nil
+}
+
+// local 生成只用于查看本机状态的安装描述，不解析版本
+func (m *Manager) local(ctx context.Context) (*Installer, *Spec, error) {
+	in, err := m.installer(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	spec, err := m.spec(ctx, utils.DetectArch(), false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return in, spec, nil
+}
+
+func (m *Manager) Install(ctx context.Context) error {
+	ctx, in, spec, err := m.prepare(ctx)
+	if err != nil {
+		return err
+	}
+	return in.Install(ctx, spec)
+}
+
+func (m *Manager) Update(ctx context.Context) error {
+	ctx, in, spec, err := m.prepare(ctx)
+	if err != nil {
+		return err
+	}
+	return in.Update(ctx, spec)
+}
+
+func (m *Manager) Uninstall(ctx context.Context) error {
+	in, spec, err := m.local(ctx)
+	if err != nil {
+		return err
+	}
+	return in.Uninstall(ctx, spec)
+}
+
+// Status 报告主程序、各发布文件及服务状态
+func (m *Manager) Status(ctx context.Context) (*soft.Status, error) {
+	in, spec, err := m.local(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return in.Status(ctx, spec), nil
+}
+
+// Plan 对比已安装版本与配置版本，供 sync 使用
+func (m *Manager) Plan(ctx context.Context) (soft.Action, string, error) {
+	in, spec, err := m.local(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	action, reason := in.Plan(ctx, spec)
+	return action, reason, nil
+}
+
+// Bundle 解析版本并将所有发布文件写入离线包
+func (m *Manager) Bundle(ctx context.Context, w *bundle.Writer) error {
+	in, err := m.installer(ctx)
+	if err != nil {
+		return err
+	}
+	spec, err := m.spec(ctx, utils.ArchType(w.Arch()), true)
+	if err != nil {
+		return err
+	}
+	return in.Bundle(ctx, w, spec)
+}
@@ -0,0 +1,148 @@
+package staticbin
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+
+	"github.com/bookandmusic/dev-tools/internal/bundle"
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/github"
+	"github.com/bookandmusic/dev-tools/internal/service"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+	"github.com/bookandmusic/dev-tools/internal/version"
+)
+
+// Artifact 一个要下载并放入安装目录的发布文件
+type Artifact struct {
+	Name    string   // 在缓存、离线包中的名称，如 containerd、runc
+	Version string   // 版本号
+	File    string   // 发布文件名，如 containerd-1.7.22-linux-amd64.tar.gz
+	URLs    []string // 下载地址，按顺序尝试
+	Strip   int      // 解压时去掉的目录层数
+	Dir     string   // 解压到安装目录下的子目录，默认 bin
+	Binary  string   // 非空表示发布文件是单个可执行文件，复制为 Dir/Binary，不解压
+	SHA256  string   // 已知的校验和，为空时按下载地址查找配置中固定的值
+	Direct  bool     // 下载时不使用 HTTP 代理，如国内镜像站
+}
+
+// File 安装时写入的配置文件，已存在时保留用户的修改
+type File struct {
+	Path    string
+	Content string
+}
+
+// Link 安装目录下的符号链接，如 k3s 提供的 kubectl
+type Link struct {
+	Name   string // 相对安装目录的链接路径
+	Target string // 链接目标
+}
+
+// Spec 一个由静态二进制和若干服务组成的软件
+type Spec struct {
+	Name           string            // manager 名称，同时是 lock 与缓存目录中的键
+	Title          string            // 提示信息中显示的名称
+	Version        string            // 要安装的版本
+	Arch           utils.ArchType    // 目标架构
+	InstallDir     string            // 安装目录
+	BinDir         string            // 可执行文件所在目录（相对安装目录），默认 bin，加入 PATH
+	Artifacts      []Artifact        // 要下载的发布文件，第一个为主程序
+	Links          []Link            // 安装后创建的符号链接
+	Files          []File            // 写入的配置文件
+	Services       []service.Spec    // 注册的服务，按顺序启动
+	ServiceManager string            // auto、systemd、openrc、sysv 或 supervised
+	Env            map[string]string // 额外写入 RootDir/.env 的变量
+	VersionArgs    []string          // 查询已安装版本的命令，第一个元素为相对 BinDir 的可执行文件
+}
+
+// binDir 返回可执行文件目录的绝对路径
+func (s *Spec) binDir() string {
+	if s.BinDir == "" {
+		return filepath.Join(s.InstallDir, "bin")
+	}
+	return filepath.Join(s.InstallDir, s.BinDir)
+}
+
+// services 返回服务描述，supervised 方式的脚本、pidfile 和日志默认放在安装目录的 run 下
+func (s *Spec) services() []service.Spec {
+	services := make([]service.Spec, len(s.Services))
+	for i, svc := range s.Services {
+		if svc.RuntimeDir == "" {
+			svc.RuntimeDir = filepath.Join(s.InstallDir, "run")
+		}
+		services[i] = svc
+	}
+	return services
+}
+
+// BinPath 返回 BinDir 下可执行文件的绝对路径，用于构造服务的 Exec
+func (s *Spec) BinPath(name string) string {
+	return filepath.Join(s.binDir(), name)
+}
+
+// GoArch 返回发布文件中常用的 amd64/arm64 架构名
+func GoArch(arch utils.ArchType) string {
+	if arch == utils.ArchAARCH64 {
+		return "arm64"
+	}
+	return "amd64"
+}
+
+// HostArch 返回离线包或当前主机的架构，不支持的架构返回错误
+func HostArch(ctx context.Context) (utils.ArchType, error) {
+	arch := utils.DetectArch()
+	if arch == utils.ArchUnknown {
+		return "", fmt.Errorf("unsupported architecture, only x86_64 and aarch64 are supported")
+	}
+	if b := bundle.FromContext(ctx); b != nil && b.Arch != string(arch) {
+		return "", fmt.Errorf("bundle is built for %s, host is %s", b.Arch, arch)
+	}
+	return arch, nil
+}
+
+// WithBundle 使用 --bundle 时打开离线包并放入 context，之后的下载都从包内读取
+func WithBundle(ctx context.Context, console ui.UI, global *config.CommonConfig) (context.Context, error) {
+	b, err := bundle.Open(console, global.Bundle, global.CacheDir)
+	if err != nil || b == nil {
+		return ctx, err
+	}
+	return bundle.WithBundle(ctx, b), nil
+}
+
+// GithubRelease 返回 GitHub release 文件的下载地址，配置了 github-proxy 时优先使用代理
+func GithubRelease(console ui.UI, global *config.CommonConfig, repo, tag, file string) []string {
+	downloadUrl := fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", repo, tag, file)
+	return utils.GithubURLs(console, global.GithubProxy, global.HttpProxy, downloadUrl)
+}
+
+// ResolveVersion 确定要安装的版本：使用离线包时取包内记录的版本，
+// 配置为完整版本时直接使用，latest 或约束（如 "~1.7"）在 GitHub release 中查找，返回 release 的 tag
+func ResolveVersion(ctx context.Context, console ui.UI, global *config.CommonConfig, name, repo, v string) (string, error) {
+	if b := bundle.FromContext(ctx); b != nil {
+		if bv := b.Version(name); bv != "" {
+			return bv, nil
+		}
+		return "", fmt.Errorf("bundle does not contain %s", name)
+	}
+	if version.IsExact(v) {
+		return v, nil
+	}
+	if utils.IsOffline(ctx) {
+		return "", fmt.Errorf("%s version must be pinned in offline mode: %w", name, utils.ErrOffline)
+	}
+	console.Info("获取%s版本 %s...", name, orDash(v))
+	return github.New(console, global).ResolveTag(ctx, repo, v, false)
+}
+
+// versionPattern 匹配 "containerd github.com/containerd/containerd v1.7.22"、"k3s version v1.30.5+k3s1" 中的版本号
+var versionPattern = regexp.MustCompile(`v?(\d+\.\d+\.\d+\S*)`)
+
+// parseVersion 从命令输出中提取不带 v 前缀的版本号
+func parseVersion(out string) string {
+	if m := versionPattern.FindStringSubmatch(out); m != nil {
+		return m[1]
+	}
+	return ""
+}
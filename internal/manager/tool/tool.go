@@ -0,0 +1,422 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/bookandmusic/dev-tools/internal/cache"
+	"github.com/bookandmusic/dev-tools/internal/config"
+	"github.com/bookandmusic/dev-tools/internal/github"
+	"github.com/bookandmusic/dev-tools/internal/lock"
+	"github.com/bookandmusic/dev-tools/internal/manager/soft/staticbin"
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+	"github.com/bookandmusic/dev-tools/internal/version"
+)
+
+// 发布文件类型
+const (
+	ArchiveTarGz  = "tar.gz"
+	ArchiveZip    = "zip"
+	ArchiveBinary = "binary"
+)
+
+// Installer 安装 config.yml 中 tools 声明的 GitHub release 工具。
+// 每个版本解压到 RootDir/tools/<name>/<version>，可执行文件链接到 RootDir/tools/bin 并加入 PATH
+type Installer struct {
+	UI     ui.UI
+	Global *config.CommonConfig
+}
+
+// Release 解析后的一次发布
+type Release struct {
+	Version string // 不带 v 前缀的版本
+	Tag     string // release tag
+	Asset   string // 发布文件名
+	Archive string // 发布文件类型
+}
+
+// templateData 模板中可用的变量
+type templateData struct {
+	Name    string
+	Version string
+	Arch    string
+	Machine string
+	OS      string
+	Asset   string
+}
+
+// Dir 返回 tools 的根目录
+func (in *Installer) Dir() string {
+	return filepath.Join(utils.ExpandAbsDir(in.Global.RootDir), "tools")
+}
+
+// BinDir 返回存放可执行文件链接的目录
+func (in *Installer) BinDir() string {
+	return filepath.Join(in.Dir(), "bin")
+}
+
+// lockKey tools 在 dtl.lock 中的键，避免与 manager 重名
+func lockKey(name string) string {
+	return "tool:" + name
+}
+
+// Find 在配置中查找工具
+func Find(tools []*config.ToolConfig, name string) (*config.ToolConfig, error) {
+	for _, t := range tools {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("tool %s is not declared in config tools", name)
+}
+
+// Validate 检查工具配置是否完整
+func Validate(t *config.ToolConfig) error {
+	switch {
+	case t.Name == "":
+		return fmt.Errorf("tool name is required")
+	case t.Repo == "":
+		return fmt.Errorf("tool %s: repo is required", t.Name)
+	case t.Asset == "":
+		return fmt.Errorf("tool %s: asset is required", t.Name)
+	}
+	if _, err := archiveType(t); err != nil {
+		return err
+	}
+	return nil
+}
+
+// archiveType 返回发布文件类型，未配置时按 Asset 的扩展名判断
+func archiveType(t *config.ToolConfig) (string, error) {
+	switch t.Archive {
+	case ArchiveTarGz, "tgz":
+		return ArchiveTarGz, nil
+	case ArchiveZip, ArchiveBinary:
+		return t.Archive, nil
+	case "":
+	default:
+		return "", fmt.Errorf("tool %s: unsupported archive type %q (tar.gz, zip, binary)", t.Name, t.Archive)
+	}
+	switch {
+	case strings.HasSuffix(t.Asset, ".tar.gz"), strings.HasSuffix(t.Asset, ".tgz"):
+		return ArchiveTarGz, nil
+	case strings.HasSuffix(t.Asset, ".zip"):
+		return ArchiveZip, nil
+	default:
+		return ArchiveBinary, nil
+	}
+}
+
+// render 渲染 asset、tag、checksum 模板
+func render(name, text string, data templateData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template %q: %w", name, text, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid %s template %q: %w", name, text, err)
+	}
+	return buf.String(), nil
+}
+
+// data 返回模板变量
+func data(t *config.ToolConfig, version string, arch utils.ArchType) templateData {
+	return templateData{
+		Name:    t.Name,
+		Version: version,
+		Arch:    staticbin.GoArch(arch),
+		Machine: string(arch),
+		OS:      "linux",
+	}
+}
+
+// Resolve 确定要安装的版本、tag 与发布文件名。版本为 latest 或约束（如 "~0.55"）时在 GitHub release 中查找，
+// prerelease 时包含预发布版本；不是语义化版本的写法按原样使用
+func (in *Installer) Resolve(ctx context.Context, t *config.ToolConfig, arch utils.ArchType) (*Release, error) {
+	if err := Validate(t); err != nil {
+		return nil, err
+	}
+	r := &Release{Version: strings.TrimPrefix(t.Version, "v")}
+	if version.IsLatest(t.Version) || version.IsConstraint(t.Version) {
+		if utils.IsOffline(ctx) {
+			return nil, fmt.Errorf("%s version must be pinned in offline mode: %w", t.Name, utils.ErrOffline)
+		}
+		in.UI.Info("Resolving %s %s from %s...", t.Name, t.Version, t.Repo)
+		tag, err := github.New(in.UI, in.Global).ResolveTag(ctx, t.Repo, t.Version, t.Prerelease)
+		if err != nil {
+			return nil, err
+		}
+		// 直接使用查询到的 tag，不依赖 tag 模板是否带 v 前缀
+		r.Tag, r.Version = tag, strings.TrimPrefix(tag, "v")
+	}
+
+	d := data(t, r.Version, arch)
+	var err error
+	if r.Tag == "" {
+		if r.Tag, err = render("tag", t.Tag, d); err != nil {
+			return nil, err
+		}
+	}
+	if r.Asset, err = render("asset", t.Asset, d); err != nil {
+		return nil, err
+	}
+	if r.Archive, err = archiveType(t); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// InstalledVersion 返回 dtl.lock 中记录的版本
+func (in *Installer) InstalledVersion(name string) string {
+	l, err := lock.Open(in.Global.RootDir)
+	if err != nil {
+		return ""
+	}
+	if r, ok := l.Get(lockKey(name)); ok {
+		return r.Version
+	}
+	return ""
+}
+
+// Install 下载并安装工具，已安装的相同版本会被重新解压
+func (in *Installer) Install(ctx context.Context, t *config.ToolConfig) error {
+	arch, err := staticbin.HostArch(ctx)
+	if err != nil {
+		return err
+	}
+	r, err := in.Resolve(ctx, t, arch)
+	if err != nil {
+		return err
+	}
+	return in.install(ctx, t, r, arch)
+}
+
+// Update 安装配置的版本（latest 时为最新版本），并删除旧版本
+func (in *Installer) Update(ctx context.Context, t *config.ToolConfig) error {
+	arch, err := staticbin.HostArch(ctx)
+	if err != nil {
+		return err
+	}
+	r, err := in.Resolve(ctx, t, arch)
+	if err != nil {
+		return err
+	}
+	old := in.InstalledVersion(t.Name)
+	if old != "" && version.Same(old, r.Version) && utils.PathExists(in.versionDir(t.Name, r.Version)) {
+		in.UI.Success("%s %s is up to date", t.Name, r.Version)
+		return nil
+	}
+	if err := in.install(ctx, t, r, arch); err != nil {
+		return err
+	}
+	if old != "" && !version.Same(old, r.Version) {
+		in.UI.Info("Removing %s %s", t.Name, old)
+		return utils.RemoveAll(ctx, in.versionDir(t.Name, old))
+	}
+	return nil
+}
+
+// Remove 删除工具的所有版本及其链接
+func (in *Installer) Remove(ctx context.Context, t *config.ToolConfig) error {
+	dir := filepath.Join(in.Dir(), t.Name)
+	for _, bin := range t.Binaries {
+		// 只删除指向该工具的链接，同名链接可能已被其他工具替换
+		link := filepath.Join(in.BinDir(), path.Base(bin))
+		if target, err := os.Readlink(link); err == nil && strings.HasPrefix(target, dir+string(os.PathSeparator)) {
+			in.UI.Info("Removing %s", link)
+			if err := utils.RemoveAll(ctx, link); err != nil {
+				return err
+			}
+		}
+	}
+	if utils.PathExists(dir) {
+		in.UI.Info("Removing %s", dir)
+		if err := utils.RemoveAll(ctx, dir); err != nil {
+			return err
+		}
+	}
+	if err := lock.Update(ctx, in.Global.RootDir, func(l *lock.Lock) { l.Delete(lockKey(t.Name)) }); err != nil {
+		return err
+	}
+	in.UI.Success("Removed %s", t.Name)
+	return nil
+}
+
+// versionDir 返回某个版本的安装目录
+func (in *Installer) versionDir(name, version string) string {
+	return filepath.Join(in.Dir(), name, version)
+}
+
+// install 下载、解压并链接可执行文件，最后记录到 dtl.lock
+func (in *Installer) install(ctx context.Context, t *config.ToolConfig, r *Release, arch utils.ArchType) error {
+	in.UI.Info("Installing %s %s (%s)", t.Name, r.Version, r.Asset)
+	file, err := in.fetch(ctx, t, r, arch)
+	if err != nil {
+		return err
+	}
+
+	dir := in.versionDir(t.Name, r.Version)
+	if err := utils.RemoveAll(ctx, dir); err != nil {
+		return err
+	}
+	if err := utils.MkdirAll(ctx, dir, 0o755); err != nil {
+		return err
+	}
+	switch r.Archive {
+	case ArchiveTarGz:
+		err = utils.ExtractTarGzWithProgress(ctx, in.UI, file, dir, t.Strip)
+	case ArchiveZip:
+		err = utils.ExtractZip(ctx, file, dir, t.Strip)
+	default:
+		dest := filepath.Join(dir, t.Binaries[0])
+		if err = utils.MkdirAll(ctx, filepath.Dir(dest), 0o755); err == nil {
+			err = utils.CopyFile(ctx, file, dest)
+		}
+	}
+	if err != nil {
+		in.UI.Error("Failed to extract %s", r.Asset)
+		return err
+	}
+
+	if err := utils.MkdirAll(ctx, in.BinDir(), 0o755); err != nil {
+		return err
+	}
+	for _, bin := range t.Binaries {
+		target, err := findBinary(ctx, dir, bin)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", t.Name, r.Version, err)
+		}
+		if utils.DryRunFromContext(ctx) == nil {
+			if err := os.Chmod(target, 0o755); err != nil {
+				return err
+			}
+		}
+		link := filepath.Join(in.BinDir(), path.Base(bin))
+		if err := utils.RunCommand(ctx, in.UI, nil, "ln", "-sfn", target, link); err != nil {
+			return err
+		}
+	}
+
+	if err := utils.UpdateEnvFile(ctx, filepath.Join(in.Global.RootDir, ".env"), map[string]string{"PATH": in.BinDir()}, "add"); err != nil {
+		in.UI.Error("Failed to update environment variables")
+		return err
+	}
+	record := lock.Record{Version: r.Version, Arch: string(arch)}
+	if err := lock.Update(ctx, in.Global.RootDir, func(l *lock.Lock) { l.Set(lockKey(t.Name), record) }); err != nil {
+		return err
+	}
+	in.UI.Success("Installed %s %s: %s", t.Name, r.Version, strings.Join(t.Binaries, ", "))
+	return nil
+}
+
+// fetch 下载发布文件到 CacheDir/tools/<name>/<version>/<arch>，并按配置的来源校验
+func (in *Installer) fetch(ctx context.Context, t *config.ToolConfig, r *Release, arch utils.ArchType) (string, error) {
+	cachePath := filepath.Join(in.Global.CacheDir, "tools", t.Name, r.Version, string(arch), r.Asset)
+	urls := staticbin.GithubRelease(in.UI, in.Global, t.Repo, r.Tag, r.Asset)
+
+	// 离线时无法获取校验文件，只能使用已有缓存
+	checksum := ""
+	if !utils.IsOffline(ctx) {
+		var err error
+		if checksum, err = in.checksum(ctx, t, r, arch, urls[len(urls)-1]); err != nil {
+			return "", err
+		}
+	}
+	if err := utils.DownloadToCache(ctx, in.UI, urls, cachePath, in.Global.DownloadOptions(checksum)); err != nil {
+		in.UI.Error("Failed to download %s", r.Asset)
+		return "", err
+	}
+	entry := cache.Entry{Name: t.Name, Version: r.Version, Arch: string(arch), SHA256: checksum, URL: urls[len(urls)-1], Path: cachePath}
+	if err := cache.Track(ctx, in.Global.CacheDir, entry); err != nil {
+		in.UI.Warning("Failed to update cache index: %v", err)
+	}
+	return cachePath, nil
+}
+
+// checksum 返回发布文件的 SHA256：固定值、release 中的校验文件，或 common.checksums
+func (in *Installer) checksum(ctx context.Context, t *config.ToolConfig, r *Release, arch utils.ArchType, downloadURL string) (string, error) {
+	if t.Checksum == "" {
+		if checksum := utils.PinnedChecksum(in.Global.Checksums, downloadURL); checksum != "" {
+			return checksum, nil
+		}
+		return in.digest(ctx, t, r)
+	}
+	if strings.HasPrefix(t.Checksum, "sha256:") {
+		return t.Checksum, nil
+	}
+
+	d := data(t, r.Version, arch)
+	d.Asset = r.Asset
+	file, err := render("checksum", t.Checksum, d)
+	if err != nil {
+		return "", err
+	}
+	var errs []string
+	for _, u := range staticbin.GithubRelease(in.UI, in.Global, t.Repo, r.Tag, file) {
+		content, err := utils.FetchText(ctx, u, in.Global.DownloadOptions(""))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", u, err))
+			continue
+		}
+		return utils.ParseChecksums(content, r.Asset)
+	}
+	return "", fmt.Errorf("failed to fetch checksum file %s: %s", file, strings.Join(errs, "; "))
+}
+
+// digest 返回 GitHub 为发布文件记录的 SHA256，较早的 release 没有记录时返回空字符串。
+// 查询 release 失败（如触发限流）时只提示，不阻止安装
+func (in *Installer) digest(ctx context.Context, t *config.ToolConfig, r *Release) (string, error) {
+	release, err := github.New(in.UI, in.Global).ReleaseByTag(ctx, t.Repo, r.Tag)
+	if err != nil {
+		if errors.Is(err, github.ErrNotFound) {
+			return "", err
+		}
+		in.UI.Warning("Cannot verify %s: %v", r.Asset, err)
+		return "", nil
+	}
+	asset, err := release.Asset(r.Asset)
+	if err != nil {
+		return "", err
+	}
+	return asset.SHA256(), nil
+}
+
+// findBinary 在解压目录中查找可执行文件：bin 含 / 时视为包内相对路径，否则按文件名查找。
+// 干跑模式下文件并未解压，返回预期路径
+func findBinary(ctx context.Context, dir, bin string) (string, error) {
+	if strings.Contains(bin, "/") || utils.DryRunFromContext(ctx) != nil {
+		p := filepath.Join(dir, bin)
+		if utils.DryRunFromContext(ctx) == nil && !utils.PathExists(p) {
+			return "", fmt.Errorf("binary %s not found in archive", bin)
+		}
+		return p, nil
+	}
+	var found string
+	err := filepath.WalkDir(dir, func(p string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !e.IsDir() && e.Name() == bin {
+			found = p
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if found == "" {
+		return "", fmt.Errorf("binary %s not found in archive", bin)
+	}
+	return found, nil
+}
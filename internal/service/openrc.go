@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// openrcManager 生成 /etc/init.d 下的 openrc-run 脚本，由 supervise-daemon 托管进程
+type openrcManager struct{}
+
+// Name 返回服务管理方式名称
+func (openrcManager) Name() string { return OpenRC }
+
+// File 返回 init 脚本路径
+func (openrcManager) File(spec Spec) string {
+	return "/etc/init.d/" + spec.Name
+}
+
+// Render 生成 openrc-run 脚本
+func (openrcManager) Render(spec Spec) string {
+	var b strings.Builder
+	b.WriteString("#!/sbin/openrc-run\n\n")
+	fmt.Fprintf(&b, "description=%s\n", shellQuote(spec.Description))
+	b.WriteString("supervisor=supervise-daemon\n")
+	fmt.Fprintf(&b, "command=%s\n", shellQuote(spec.Exec))
+	fmt.Fprintf(&b, "command_args=%s\n", shellQuote(strings.Join(spec.Args, " ")))
+	// 与进程自身的 pidfile（如 /var/run/docker.pid）区分开
+	fmt.Fprintf(&b, "pidfile=/run/dtl-%s.pid\n", spec.Name)
+	fmt.Fprintf(&b, "output_log=/var/log/%s.log\n", spec.Name)
+	fmt.Fprintf(&b, "error_log=/var/log/%s.log\n", spec.Name)
+	if spec.WorkDir != "" {
+		fmt.Fprintf(&b, "directory=%s\n", shellQuote(spec.WorkDir))
+	}
+	b.WriteString("respawn_delay=2\n")
+	b.WriteString("respawn_max=3\n")
+	b.WriteString("respawn_period=60\n")
+	b.WriteString("extra_started_commands=\"reload\"\n")
+	for _, pair := range envPairs(spec) {
+		fmt.Fprintf(&b, "export %s=%s\n", pair[0], shellQuote(pair[1]))
+	}
+	b.WriteString(`
+depend() {
+	need net
+	after firewall
+}
+
+reload() {
+	ebegin "Reloading ${RC_SVCNAME}"
+	supervise-daemon "${RC_SVCNAME}" --signal HUP --pidfile "${pidfile}"
+	eend $?
+}
+`)
+	return b.String()
+}
+
+// Command 返回 rc-update / rc-service 命令，openrc 不需要重新加载服务定义
+func (openrcManager) Command(spec Spec, action Action) string {
+	switch action {
+	case Refresh:
+		return ""
+	case Enable:
+		return fmt.Sprintf("sudo rc-update add %s default", spec.Name)
+	case Disable:
+		return fmt.Sprintf("sudo rc-update del %s default", spec.Name)
+	default:
+		return fmt.Sprintf("sudo rc-service %s %s", spec.Name, action)
+	}
+}
+
+// IsActive 通过 rc-service status 判断服务是否运行
+func (openrcManager) IsActive(ctx context.Context, spec Spec) bool {
+	if _, err := exec.LookPath("rc-service"); err != nil {
+		return false
+	}
+	_, err := utils.CommandOutput(ctx, "rc-service", spec.Name, "status")
+	return err == nil
+}
+
+// IsEnabled 判断服务是否在 default 运行级别中
+func (openrcManager) IsEnabled(ctx context.Context, spec Spec) bool {
+	return utils.PathExists("/etc/runlevels/default/" + spec.Name)
+}
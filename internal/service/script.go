@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// scriptManager 用 POSIX sh 脚本通过 pidfile 管理进程。
+// sysv 为 true 时脚本作为 /etc/init.d 下的 SysV init 脚本注册，
+// 否则作为没有 init 系统时的兜底方式，脚本、pidfile 和日志都放在 RuntimeDir 中
+type scriptManager struct {
+	sysv bool
+}
+
+// Name 返回服务管理方式名称
+func (s scriptManager) Name() string {
+	if s.sysv {
+		return SysV
+	}
+	return Supervised
+}
+
+// File 返回脚本路径
+func (s scriptManager) File(spec Spec) string {
+	if s.sysv {
+		return "/etc/init.d/" + spec.Name
+	}
+	return filepath.Join(spec.RuntimeDir, spec.Name+".sh")
+}
+
+// pidFile 返回脚本记录的 pid 文件，与进程自身的 pidfile 区分开
+func (s scriptManager) pidFile(spec Spec) string {
+	if s.sysv {
+		return fmt.Sprintf("/var/run/dtl-%s.pid", spec.Name)
+	}
+	return filepath.Join(spec.RuntimeDir, spec.Name+".pid")
+}
+
+// logFile 返回进程输出的日志文件
+func (s scriptManager) logFile(spec Spec) string {
+	if s.sysv {
+		return fmt.Sprintf("/var/log/%s.log", spec.Name)
+	}
+	return filepath.Join(spec.RuntimeDir, spec.Name+".log")
+}
+
+// Render 生成管理脚本
+func (s scriptManager) Render(spec Spec) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	if s.sysv {
+		fmt.Fprintf(&b, `### BEGIN INIT INFO
+# Provides:          %s
+# Required-Start:    $remote_fs $network
+# Required-Stop:     $remote_fs $network
+# Default-Start:     2 3 4 5
+# Default-Stop:      0 1 6
+# Short-Description: %s
+### END INIT INFO
+`, spec.Name, spec.Description)
+	} else {
+		fmt.Fprintf(&b, "# %s\n", spec.Description)
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "NAME=%s\n", shellQuote(spec.Name))
+	fmt.Fprintf(&b, "PIDFILE=%s\n", shellQuote(s.pidFile(spec)))
+	fmt.Fprintf(&b, "LOGFILE=%s\n", shellQuote(s.logFile(spec)))
+	for _, pair := range envPairs(spec) {
+		fmt.Fprintf(&b, "export %s=%s\n", pair[0], shellQuote(pair[1]))
+	}
+	workDir := spec.WorkDir
+	if workDir == "" {
+		workDir = "/"
+	}
+	fmt.Fprintf(&b, `
+is_running() {
+	[ -f "$PIDFILE" ] && kill -0 "$(cat "$PIDFILE")" 2>/dev/null
+}
+
+start() {
+	if is_running; then
+		echo "$NAME is already running"
+		return 0
+	fi
+	cd %s || return 1
+	nohup %s >>"$LOGFILE" 2>&1 </dev/null &
+	echo $! >"$PIDFILE"
+	sleep 1
+	if ! is_running; then
+		echo "$NAME failed to start, see $LOGFILE"
+		rm -f "$PIDFILE"
+		return 1
+	fi
+	echo "$NAME started"
+}
+
+stop() {
+	if ! is_running; then
+		rm -f "$PIDFILE"
+		return 0
+	fi
+	pid=$(cat "$PIDFILE")
+	kill "$pid"
+	i=0
+	while kill -0 "$pid" 2>/dev/null; do
+		i=$((i + 1))
+		if [ "$i" -ge 30 ]; then
+			kill -9 "$pid"
+			break
+		fi
+		sleep 1
+	done
+	rm -f "$PIDFILE"
+	echo "$NAME stopped"
+}
+
+case "$1" in
+start) start ;;
+stop) stop ;;
+restart)
+	stop
+	start
+	;;
+reload) is_running && kill -HUP "$(cat "$PIDFILE")" ;;
+status)
+	if is_running; then
+		echo "$NAME is running"
+	else
+		echo "$NAME is not running"
+		exit 3
+	fi
+	;;
+*)
+	echo "Usage: $0 {start|stop|restart|reload|status}"
+	exit 1
+	;;
+esac
+`, shellQuote(workDir), commandLine(spec))
+	return b.String()
+}
+
+// Command 返回执行脚本的命令，SysV 通过 update-rc.d 或 chkconfig 注册开机启动，
+// 兜底方式不支持开机启动
+func (s scriptManager) Command(spec Spec, action Action) string {
+	switch action {
+	case Refresh:
+		return ""
+	case Enable:
+		if !s.sysv {
+			return ""
+		}
+		return fmt.Sprintf("if command -v update-rc.d >/dev/null 2>&1; then sudo update-rc.d %[1]s defaults; "+
+			"elif command -v chkconfig >/dev/null 2>&1; then sudo chkconfig --add %[1]s; fi", spec.Name)
+	case Disable:
+		if !s.sysv {
+			return ""
+		}
+		return fmt.Sprintf("if command -v update-rc.d >/dev/null 2>&1; then sudo update-rc.d -f %[1]s remove; "+
+			"elif command -v chkconfig >/dev/null 2>&1; then sudo chkconfig --del %[1]s; fi", spec.Name)
+	default:
+		return fmt.Sprintf("%s%s %s", sudo(spec), shellQuote(s.File(spec)), action)
+	}
+}
+
+// IsActive 读取 pidfile 并检查进程是否存在；进程可能属于 root，因此不使用 kill -0
+func (s scriptManager) IsActive(_ context.Context, spec Spec) bool {
+	data, err := os.ReadFile(s.pidFile(spec))
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return false
+	}
+	return utils.PathExists(fmt.Sprintf("/proc/%d", pid))
+}
+
+// IsEnabled 检查 /etc/rc?.d 中是否有启动链接，兜底方式总是返回 false
+func (s scriptManager) IsEnabled(_ context.Context, spec Spec) bool {
+	if !s.sysv {
+		return false
+	}
+	matches, _ := filepath.Glob(fmt.Sprintf("/etc/rc[2-5].d/S??%s", spec.Name))
+	if len(matches) == 0 {
+		matches, _ = filepath.Glob(fmt.Sprintf("/etc/rc.d/rc[2-5].d/S??%s", spec.Name))
+	}
+	return len(matches) > 0
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bookandmusic/dev-tools/internal/ui"
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// 支持的服务管理方式，配置中的 service-manager 取这些值
+const (
+	Auto       = "auto"
+	Systemd    = "systemd"
+	OpenRC     = "openrc"
+	SysV       = "sysv"
+	Supervised = "supervised"
+)
+
+// Action 服务操作
+type Action string
+
+const (
+	Refresh Action = "refresh" // 重新读取服务定义文件，如 systemctl daemon-reload
+	Enable  Action = "enable"  // 开机自启
+	Disable Action = "disable" // 取消开机自启
+	Start   Action = "start"   // 启动
+	Stop    Action = "stop"    // 停止
+	Restart Action = "restart" // 重启
+	Reload  Action = "reload"  // 重新加载配置（SIGHUP）
+)
+
+// Spec 描述一个要注册为服务的常驻进程
+type Spec struct {
+	Name        string            // 服务名，如 docker
+	Description string            // 服务描述
+	Exec        string            // 可执行文件绝对路径
+	Args        []string          // 启动参数
+	Env         map[string]string // 环境变量，PATH 会放在系统 PATH 之前
+	WorkDir     string            // 工作目录
+	Notify      bool              // 进程支持 sd_notify（systemd Type=notify）
+	User        bool              // 用户级服务：不使用 sudo，systemd 使用 --user
+	RuntimeDir  string            // supervised 模式下启动脚本、pidfile 与日志所在目录
+	After       []string          // systemd: 在这些 unit 之后启动
+	Requires    []string          // systemd: 依赖的 unit，如 docker.socket
+	Systemd     []string          // systemd: 追加到 [Service] 段的其他配置
+	Socket      *Socket           // systemd: 通过 <name>.socket 激活，其他方式由进程自己创建 socket
+}
+
+// Socket systemd socket 激活的监听地址与权限
+type Socket struct {
+	Listen string // 监听的 unix socket 路径
+	Mode   string // 权限，如 0660
+	Group  string // 所属组
+}
+
+// Manager 一种服务管理方式（systemd、OpenRC、SysV init 脚本或直接托管进程）
+type Manager interface {
+	// Name 返回服务管理方式名称
+	Name() string
+	// File 返回服务定义文件（unit 或 init 脚本）的路径
+	File(spec Spec) string
+	// Render 生成服务定义文件内容
+	Render(spec Spec) string
+	// Command 返回执行动作的 shell 命令，不支持或无需执行时返回空字符串
+	Command(spec Spec, action Action) string
+	// IsActive 判断服务是否正在运行
+	IsActive(ctx context.Context, spec Spec) bool
+	// IsEnabled 判断服务是否开机自启
+	IsEnabled(ctx context.Context, spec Spec) bool
+}
+
+// New 根据名称返回服务管理方式，name 为空或 auto 时自动探测
+func New(name string, user bool) (Manager, error) {
+	switch name {
+	case "", Auto:
+		return Detect(user), nil
+	case Systemd:
+		return systemdManager{}, nil
+	case OpenRC:
+		if user {
+			return nil, fmt.Errorf("openrc does not support user services, use systemd or supervised")
+		}
+		return openrcManager{}, nil
+	case SysV:
+		if user {
+			return nil, fmt.Errorf("sysv init does not support user services, use systemd or supervised")
+		}
+		return scriptManager{sysv: true}, nil
+	case Supervised:
+		return scriptManager{}, nil
+	default:
+		return nil, fmt.Errorf("unknown service manager: %s (auto, systemd, openrc, sysv, supervised)", name)
+	}
+}
+
+// Detect 探测当前系统的 init：systemd > OpenRC > SysV，都不可用时直接托管进程
+func Detect(user bool) Manager {
+	// 与 sd_booted() 相同的判断方式
+	if utils.PathExists("/run/systemd/system") {
+		return systemdManager{}
+	}
+	if user {
+		return scriptManager{}
+	}
+	if _, err := exec.LookPath("rc-service"); err == nil && utils.PathExists("/run/openrc") {
+		return openrcManager{}
+	}
+	if comm, err := os.ReadFile("/proc/1/comm"); err == nil && strings.TrimSpace(string(comm)) == "init" && utils.PathExists("/etc/init.d") {
+		return scriptManager{sysv: true}
+	}
+	return scriptManager{}
+}
+
+// Files 返回服务用到的所有定义文件，systemd socket 激活时包含 socket unit
+func Files(m Manager, spec Spec) []string {
+	files := []string{m.File(spec)}
+	if sm, ok := m.(systemdManager); ok && spec.Socket != nil {
+		files = append(files, sm.socketFile(spec))
+	}
+	return files
+}
+
+// Install 写入服务定义文件，脚本会加上可执行权限
+func Install(ctx context.Context, console ui.UI, env map[string]string, m Manager, spec Spec) error {
+	contents := map[string]string{m.File(spec): m.Render(spec)}
+	if sm, ok := m.(systemdManager); ok && spec.Socket != nil {
+		contents[sm.socketFile(spec)] = sm.renderSocket(spec)
+	}
+	for _, file := range Files(m, spec) {
+		content := contents[file]
+		console.Info("正在生成%s文件...", file)
+		if err := run(ctx, console, env, spec, "mkdir", "-p", filepath.Dir(file)); err != nil {
+			return err
+		}
+		if err := utils.TeeFile(ctx, console, env, file, content, !spec.User); err != nil {
+			return err
+		}
+		if strings.HasPrefix(content, "#!") {
+			if err := run(ctx, console, env, spec, "chmod", "755", file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Remove 删除服务定义文件
+func Remove(ctx context.Context, console ui.UI, env map[string]string, m Manager, spec Spec) error {
+	for _, file := range Files(m, spec) {
+		if !utils.PathExists(file) {
+			continue
+		}
+		console.Info("删除服务文件: %s", file)
+		if err := run(ctx, console, env, spec, "rm", "-f", file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Commands 将多个动作拼接为一条 shell 命令，跳过不需要执行的动作
+func Commands(m Manager, spec Spec, actions ...Action) string {
+	var cmds []string
+	for _, action := range actions {
+		if cmd := m.Command(spec, action); cmd != "" {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return strings.Join(cmds, " && ")
+}
+
+// Run 依次执行动作
+func Run(ctx context.Context, console ui.UI, env map[string]string, m Manager, spec Spec, actions ...Action) error {
+	cmd := Commands(m, spec, actions...)
+	if cmd == "" {
+		return nil
+	}
+	return utils.RunCommand(ctx, console, env, "bash", "-c", cmd)
+}
+
+// State 返回 "active/enabled" 形式的状态，用于 status 输出
+func State(ctx context.Context, m Manager, spec Spec) string {
+	active, enabled := "inactive", "disabled"
+	if m.IsActive(ctx, spec) {
+		active = "active"
+	}
+	if m.IsEnabled(ctx, spec) {
+		enabled = "enabled"
+	}
+	return active + "/" + enabled
+}
+
+// run 系统级服务加 sudo 执行命令
+func run(ctx context.Context, console ui.UI, env map[string]string, spec Spec, args ...string) error {
+	if !spec.User {
+		args = append([]string{"sudo"}, args...)
+	}
+	return utils.RunCommand(ctx, console, env, args[0], args[1:]...)
+}
+
+// sudo 系统级服务的命令前缀
+func sudo(spec Spec) string {
+	if spec.User {
+		return ""
+	}
+	return "sudo "
+}
+
+// envPairs 按键排序返回环境变量，PATH 追加系统默认路径
+func envPairs(spec Spec) [][2]string {
+	keys := make([]string, 0, len(spec.Env))
+	for k := range spec.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([][2]string, 0, len(keys))
+	for _, k := range keys {
+		v := spec.Env[k]
+		if k == "PATH" {
+			v += ":/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+		}
+		pairs = append(pairs, [2]string{k, v})
+	}
+	return pairs
+}
+
+// shellQuote 用单引号包裹参数
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// commandLine 返回带引号的完整启动命令
+func commandLine(spec Spec) string {
+	parts := []string{shellQuote(spec.Exec)}
+	for _, arg := range spec.Args {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
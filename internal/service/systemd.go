@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bookandmusic/dev-tools/internal/utils"
+)
+
+// systemdManager 生成 systemd unit，用户级服务写入 ~/.config/systemd/user
+type systemdManager struct{}
+
+// Name 返回服务管理方式名称
+func (systemdManager) Name() string { return Systemd }
+
+// File 返回 unit 文件路径
+func (systemdManager) File(spec Spec) string {
+	if spec.User {
+		return utils.ExpandAbsDir(fmt.Sprintf("~/.config/systemd/user/%s.service", spec.Name))
+	}
+	return fmt.Sprintf("/etc/systemd/system/%s.service", spec.Name)
+}
+
+// Render 生成 unit 文件内容
+func (systemdManager) Render(spec Spec) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s\n", spec.Description)
+	after, requires := spec.After, spec.Requires
+	if len(after) == 0 && !spec.User {
+		after = []string{"network.target"}
+	}
+	if spec.Socket != nil {
+		after = append(after, spec.Name+".socket")
+		requires = append(requires, spec.Name+".socket")
+	}
+	if len(after) > 0 {
+		fmt.Fprintf(&b, "After=%s\n", strings.Join(after, " "))
+	}
+	if len(requires) > 0 {
+		fmt.Fprintf(&b, "Requires=%s\n", strings.Join(requires, " "))
+	}
+
+	b.WriteString("\n[Service]\n")
+	if spec.Notify {
+		b.WriteString("Type=notify\n")
+	}
+	if spec.WorkDir != "" {
+		fmt.Fprintf(&b, "WorkingDirectory=%s\n", spec.WorkDir)
+	}
+	for _, pair := range envPairs(spec) {
+		fmt.Fprintf(&b, "Environment=\"%s=%s\"\n", pair[0], pair[1])
+	}
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(append([]string{spec.Exec}, spec.Args...), " "))
+	b.WriteString("ExecReload=/bin/kill -s HUP $MAINPID\n")
+	b.WriteString("Restart=always\n")
+	b.WriteString("StartLimitBurst=3\n")
+	b.WriteString("StartLimitIntervalSec=60\n")
+	for _, line := range spec.Systemd {
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("\n[Install]\n")
+	if spec.User {
+		b.WriteString("WantedBy=default.target\n")
+	} else {
+		b.WriteString("WantedBy=multi-user.target\n")
+	}
+	return b.String()
+}
+
+// socketFile 返回 socket unit 文件路径
+func (m systemdManager) socketFile(spec Spec) string {
+	return strings.TrimSuffix(m.File(spec), ".service") + ".socket"
+}
+
+// renderSocket 生成 socket unit 文件内容
+func (systemdManager) renderSocket(spec Spec) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=%s Socket\n", spec.Description)
+	b.WriteString("\n[Socket]\n")
+	fmt.Fprintf(&b, "ListenStream=%s\n", spec.Socket.Listen)
+	if spec.Socket.Mode != "" {
+		fmt.Fprintf(&b, "SocketMode=%s\n", spec.Socket.Mode)
+	}
+	if !spec.User {
+		b.WriteString("SocketUser=root\n")
+	}
+	if spec.Socket.Group != "" {
+		fmt.Fprintf(&b, "SocketGroup=%s\n", spec.Socket.Group)
+	}
+	b.WriteString("\n[Install]\n")
+	b.WriteString("WantedBy=sockets.target\n")
+	return b.String()
+}
+
+// Command 返回 systemctl 命令，socket 激活时 enable/disable/stop 同时作用于 socket unit，
+// 否则停止服务后 socket 仍会再次拉起服务
+func (systemdManager) Command(spec Spec, action Action) string {
+	if action == Refresh {
+		return systemctl(spec) + " daemon-reload"
+	}
+	units := spec.Name
+	if spec.Socket != nil && (action == Enable || action == Disable || action == Stop) {
+		units = spec.Name + ".socket " + spec.Name
+	}
+	return fmt.Sprintf("%s %s %s", systemctl(spec), action, units)
+}
+
+// IsActive 通过 systemctl is-active 判断服务是否运行
+func (systemdManager) IsActive(ctx context.Context, spec Spec) bool {
+	out, _ := utils.CommandOutput(ctx, "systemctl", query(spec, "is-active", spec.Name)...)
+	return out == "active"
+}
+
+// IsEnabled 通过 systemctl is-enabled 判断服务是否开机自启
+func (systemdManager) IsEnabled(ctx context.Context, spec Spec) bool {
+	out, _ := utils.CommandOutput(ctx, "systemctl", query(spec, "is-enabled", spec.Name)...)
+	return out == "enabled"
+}
+
+// systemctl 返回 "sudo systemctl" 或 "systemctl --user"
+func systemctl(spec Spec) string {
+	if spec.User {
+		return "systemctl --user"
+	}
+	return "sudo systemctl"
+}
+
+// query 查询状态用的 systemctl 参数，查询不需要 sudo
+func query(spec Spec, args ...string) []string {
+	if spec.User {
+		return append([]string{"--user"}, args...)
+	}
+	return args
+}
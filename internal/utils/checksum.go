@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FileSHA256 计算文件的 SHA256（小写十六进制）
+func FileSHA256(filePath string) (string, error) {
+	f, err := os.Open(filepath.Clean(filePath))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// normalizeChecksum 去掉 "sha256:" 前缀并转为小写
+func normalizeChecksum(checksum string) string {
+	checksum = strings.TrimSpace(checksum)
+	checksum = strings.TrimPrefix(checksum, "sha256:")
+	return strings.ToLower(checksum)
+}
+
+// VerifySHA256 校验文件的 SHA256，expected 可以带 "sha256:" 前缀
+func VerifySHA256(filePath, expected string) error {
+	actual, err := FileSHA256(filePath)
+	if err != nil {
+		return err
+	}
+	if actual != normalizeChecksum(expected) {
+		return fmt.Errorf("sha256 mismatch for %s: expected %s, got %s", filepath.Base(filePath), normalizeChecksum(expected), actual)
+	}
+	return nil
+}
+
+// ParseChecksums 从 checksums.txt 或 *.sha256 文件内容中找出 fileName 的 SHA256
+// 支持 "<hash>  <file>"、"<hash> *<file>" 以及只包含 hash 的单行文件
+func ParseChecksums(content, fileName string) (string, error) {
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 1 && len(lines) == 1:
+			return normalizeChecksum(fields[0]), nil
+		case len(fields) >= 2:
+			name := strings.TrimPrefix(fields[1], "*")
+			if name == fileName || path.Base(name) == fileName {
+				return normalizeChecksum(fields[0]), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s", fileName)
+}
+
+// PinnedChecksum 在配置的 checksums 中查找下载地址对应的 SHA256
+// key 为下载地址的路径后缀，如 "docker-26.1.0.tgz" 或 "x86_64/docker-26.1.0.tgz"，多个匹配时取最长的 key
+func PinnedChecksum(checksums map[string]string, downloadURL string) string {
+	var matched, checksum string
+	for key, value := range checksums {
+		key = strings.TrimPrefix(key, "/")
+		if !strings.HasSuffix(downloadURL, "/"+key) || len(key) <= len(matched) {
+			continue
+		}
+		matched, checksum = key, value
+	}
+	return checksum
+}
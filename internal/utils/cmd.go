@@ -24,7 +24,12 @@ func (w *uiWriter) Write(p []byte) (n int, err error) {
 }
 
 // RunCmd 执行命令，实时输出到 UI
+// 干跑模式下只记录命令，不执行
 func RunCommand(ctx context.Context, u ui.UI, env map[string]string, name string, args ...string) error {
+	if d := DryRunFromContext(ctx); d != nil {
+		d.Record("command", name+" "+strings.Join(args, " "), "")
+		return nil
+	}
 	u.Info(name + " " + strings.Join(args, " "))
 
 	for k, v := range env {
@@ -44,3 +49,9 @@ func RunCommand(ctx context.Context, u ui.UI, env map[string]string, name string
 	}
 	return nil
 }
+
+// CommandOutput 执行命令并返回去除首尾空白的标准输出，不输出到 UI，用于探测状态
+func CommandOutput(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	return strings.TrimSpace(string(out)), err
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext unified diff 中每个变更块保留的上下文行数
+const diffContext = 3
+
+type diffLine struct {
+	op   byte // ' ', '-', '+'
+	text string
+}
+
+// UnifiedDiff 生成 oldText -> newText 的 unified diff，内容相同时返回空字符串
+func UnifiedDiff(name, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	lines := diffLines(splitLines(oldText), splitLines(newText))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a%s\n+++ b%s\n", name, name)
+
+	// 按上下文行数把变更行分组输出
+	for i := 0; i < len(lines); {
+		if lines[i].op == ' ' {
+			i++
+			continue
+		}
+		start := max(i-diffContext, 0)
+		end := i
+		for end < len(lines) {
+			if lines[end].op != ' ' {
+				end++
+				continue
+			}
+			// 连续相同行超过两倍上下文则结束当前块
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next == len(lines) || next-end > 2*diffContext {
+				end = min(end+diffContext, len(lines))
+				break
+			}
+			end = next
+		}
+
+		oldStart, newStart := hunkStart(lines, start)
+		oldCount, newCount := 0, 0
+		for _, l := range lines[start:end] {
+			if l.op != '+' {
+				oldCount++
+			}
+			if l.op != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, l := range lines[start:end] {
+			fmt.Fprintf(&b, "%c%s\n", l.op, l.text)
+		}
+		i = end
+	}
+	return b.String()
+}
+
+// hunkStart 计算变更块在新旧文件中的起始行号（从 1 开始）
+func hunkStart(lines []diffLine, start int) (int, int) {
+	oldLine, newLine := 1, 1
+	for _, l := range lines[:start] {
+		if l.op != '+' {
+			oldLine++
+		}
+		if l.op != '-' {
+			newLine++
+		}
+	}
+	return oldLine, newLine
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines 基于最长公共子序列计算逐行差异
+func diffLines(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var result []diffLine
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, diffLine{'-', a[i]})
+			i++
+		default:
+			result = append(result, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		result = append(result, diffLine{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		result = append(result, diffLine{'+', b[j]})
+	}
+	return result
+}
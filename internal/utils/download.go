@@ -1,22 +1,86 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	progressbar "github.com/schollz/progressbar/v3"
 
 	"github.com/bookandmusic/dev-tools/internal/ui"
 )
 
+const userAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"
+
+// maxBackoff 重试间隔的上限
+const maxBackoff = 30 * time.Second
+
+// DownloadOptions 下载参数，零值表示不设超时、不重试、不校验
+type DownloadOptions struct {
+	HttpProxy      string
+	Checksum       string        // 期望的 SHA256，为空时不校验
+	ConnectTimeout time.Duration // 建立连接（含 TLS 握手）的超时时间
+	ReadTimeout    time.Duration // 等待响应头以及两次读取之间的最长间隔
+	Retries        int           // 每个地址失败后的重试次数
+}
+
+// permanentError 重试也无法成功的错误（如 404、checksum 不匹配），直接换下一个地址
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
 // DownloadFileWithProgress 下载指定 URL 的文件到 destPath，并通过传入的 UI 输出提示信息和进度。
-func DownloadFileWithProgress(downloadUrl, destPath string, console ui.UI, httpProxy string) error {
-	// 校验文件路径安全性
+// 干跑模式下只记录下载地址
+func DownloadFileWithProgress(ctx context.Context, downloadUrl, destPath string, console ui.UI, httpProxy string) error {
+	return DownloadWithMirrors(ctx, console, []string{downloadUrl}, destPath, DownloadOptions{HttpProxy: httpProxy})
+}
+
+// DownloadToCache 确保 destPath 是 urls 对应的完整文件。
+// 已缓存且校验通过时跳过下载；校验失败的缓存会被删除后重新下载；opts.Checksum 为空时无法校验，直接使用已有缓存
+func DownloadToCache(ctx context.Context, console ui.UI, urls []string, destPath string, opts DownloadOptions) error {
+	if PathExists(destPath) {
+		if opts.Checksum == "" {
+			console.Info("Using cached file: %s", destPath)
+			return nil
+		}
+		err := VerifySHA256(destPath, opts.Checksum)
+		if err == nil {
+			console.Info("Using cached file: %s (sha256 verified)", destPath)
+			return nil
+		}
+		console.Warning("Cached file is corrupt, downloading again: %v", err)
+		if err := RemoveAll(ctx, destPath); err != nil {
+			return err
+		}
+	}
+	return DownloadWithMirrors(ctx, console, urls, destPath, opts)
+}
+
+// DownloadWithMirrors 依次尝试 urls 中的地址，每个地址按指数退避重试 opts.Retries 次。
+// 数据先写入 destPath.part，中断后再次下载会通过 Range 请求续传，校验通过后才重命名为 destPath
+func DownloadWithMirrors(ctx context.Context, console ui.UI, urls []string, destPath string, opts DownloadOptions) error {
 	cleanDestPath := filepath.Clean(destPath)
+	if len(urls) == 0 {
+		return fmt.Errorf("no download url for %s", filepath.Base(cleanDestPath))
+	}
+	if IsOffline(ctx) {
+		return fmt.Errorf("cannot download %s: %w", filepath.Base(cleanDestPath), ErrOffline)
+	}
+
+	if d := DryRunFromContext(ctx); d != nil {
+		d.Record("download", cleanDestPath, strings.Join(urls, "\n"))
+		return nil
+	}
 
 	// 确保目标文件目录存在
 	if err := os.MkdirAll(filepath.Dir(cleanDestPath), os.ModePerm); err != nil {
@@ -24,52 +88,119 @@ func DownloadFileWithProgress(downloadUrl, destPath string, console ui.UI, httpP
 		return err
 	}
 
-	// 发起 HTTP 请求
-	req, err := http.NewRequest("GET", downloadUrl, nil)
-	if err != nil {
-		return err
+	var errs []string
+	for i, downloadUrl := range urls {
+		if i > 0 {
+			console.Warning("Trying mirror %s", downloadUrl)
+		}
+		err := downloadWithRetry(ctx, console, downloadUrl, cleanDestPath, opts)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		console.Error("Download from %s failed: %v", downloadUrl, err)
+		errs = append(errs, fmt.Sprintf("%s: %v", downloadUrl, err))
 	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36")
+	return fmt.Errorf("download %s failed: %s", filepath.Base(cleanDestPath), strings.Join(errs, "; "))
+}
 
-	// 配置 HTTP 客户端
-	client := &http.Client{}
-	if httpProxy != "" {
-		proxyURL, err := url.Parse(httpProxy)
-		if err != nil {
-			console.Error("Invalid proxy URL: %v", err)
+// downloadWithRetry 对单个地址按指数退避重试
+func downloadWithRetry(ctx context.Context, console ui.UI, downloadUrl, destPath string, opts DownloadOptions) error {
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		err := downloadOnce(ctx, console, downloadUrl, destPath, opts)
+		var permanent *permanentError
+		if err == nil || errors.As(err, &permanent) || attempt >= opts.Retries || ctx.Err() != nil {
 			return err
 		}
-		client.Transport = &http.Transport{
-			Proxy: http.ProxyURL(proxyURL),
+		console.Warning("Download interrupted (%v), retrying in %s (%d/%d)", err, backoff, attempt+1, opts.Retries)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
 		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// downloadOnce 下载一次，已有 .part 文件时从断点继续
+func downloadOnce(ctx context.Context, console ui.UI, downloadUrl, destPath string, opts DownloadOptions) error {
+	partPath := destPath + ".part"
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	client, err := newHTTPClient(opts)
+	if err != nil {
+		console.Error("Invalid proxy URL: %v", err)
+		return &permanentError{err}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 发起 HTTP 请求
+	req, err := http.NewRequestWithContext(ctx, "GET", downloadUrl, nil)
+	if err != nil {
+		return &permanentError{err}
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
 	}
 
-	// 获取响应
 	resp, err := client.Do(req)
 	if err != nil {
-		console.Error("Request failed: %v", err)
 		return err
 	}
 	defer resp.Body.Close()
 
-	// 检查响应状态
-	if resp.StatusCode != http.StatusOK {
+	// 读取超时：每次读到数据后重置计时器，超时则取消请求
+	var timer *time.Timer
+	if opts.ReadTimeout > 0 {
+		timer = time.AfterFunc(opts.ReadTimeout, cancel)
+		defer timer.Stop()
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY
+	total := resp.ContentLength
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		if offset > 0 {
+			console.Info("Resuming %s from %d bytes", filepath.Base(destPath), offset)
+		}
+		flags |= os.O_APPEND
+		total = contentRangeTotal(resp.Header.Get("Content-Range"), offset+resp.ContentLength)
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// .part 已不可用（如服务端文件变化），删除后重新下载
+		_ = os.Remove(partPath)
+		return fmt.Errorf("HTTP %d, restarting download", resp.StatusCode)
+	case resp.StatusCode == http.StatusOK:
+		// 服务端不支持 Range，从头下载
+		flags |= os.O_TRUNC
+		offset = 0
+	default:
 		console.Error("Download failed, status code: %d", resp.StatusCode)
-		return fmt.Errorf("HTTP %d", resp.StatusCode)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout {
+			return fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		return &permanentError{fmt.Errorf("HTTP %d", resp.StatusCode)}
 	}
 
-	// 创建文件
-	outFile, err := os.Create(cleanDestPath)
+	outFile, err := os.OpenFile(partPath, flags, 0o600)
 	if err != nil {
-		console.Error("Failed to create file %s: %v", cleanDestPath, err)
-		return err
+		console.Error("Failed to create file %s: %v", partPath, err)
+		return &permanentError{err}
 	}
 	defer outFile.Close()
 
 	// 设置进度条
 	bar := progressbar.NewOptions64(
-		resp.ContentLength,
-		progressbar.OptionSetDescription(fmt.Sprintf("Downloading %s", filepath.Base(cleanDestPath))),
+		total,
+		progressbar.OptionSetDescription(fmt.Sprintf("Downloading %s", filepath.Base(destPath))),
 		progressbar.OptionSetWidth(40),
 		progressbar.OptionShowBytes(true),
 		progressbar.OptionSetTheme(progressbar.Theme{
@@ -80,9 +211,121 @@ func DownloadFileWithProgress(downloadUrl, destPath string, console ui.UI, httpP
 			BarEnd:        "]",
 		}),
 	)
+	_ = bar.Set64(offset)
 
 	// 下载文件并显示进度
-	_, err = io.Copy(io.MultiWriter(outFile, bar), resp.Body)
+	var body io.Reader = resp.Body
+	if timer != nil {
+		body = &timeoutReader{r: resp.Body, timer: timer, timeout: opts.ReadTimeout}
+	}
+	written, err := io.Copy(io.MultiWriter(outFile, bar), body)
 	console.Println("")
-	return err
+	if err != nil {
+		return err
+	}
+	if err := outFile.Close(); err != nil {
+		return err
+	}
+	if total > 0 && offset+written != total {
+		return fmt.Errorf("incomplete download: got %d of %d bytes", offset+written, total)
+	}
+
+	if opts.Checksum != "" {
+		if err := VerifySHA256(partPath, opts.Checksum); err != nil {
+			console.Error("Checksum mismatch for %s", filepath.Base(destPath))
+			_ = os.Remove(partPath)
+			return &permanentError{err}
+		}
+	}
+
+	// .part 的权限为 0600，与直接创建的文件保持一致
+	// #nosec G302 -- 缓存中的安装包不包含敏感信息
+	if err := os.Chmod(partPath, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(partPath, destPath)
+}
+
+// contentRangeTotal 解析 "bytes 100-199/200" 中的总大小，无法解析时返回 fallback
+func contentRangeTotal(contentRange string, fallback int64) int64 {
+	idx := strings.LastIndex(contentRange, "/")
+	if idx < 0 {
+		return fallback
+	}
+	total, err := strconv.ParseInt(contentRange[idx+1:], 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return total
+}
+
+// timeoutReader 每次读到数据后重置读取超时计时器
+type timeoutReader struct {
+	r       io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (t *timeoutReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if n > 0 {
+		t.timer.Reset(t.timeout)
+	}
+	return n, err
+}
+
+// FetchText 下载小文件（如 checksums.txt）并返回内容
+func FetchText(ctx context.Context, downloadUrl string, opts DownloadOptions) (string, error) {
+	if IsOffline(ctx) {
+		return "", ErrOffline
+	}
+	client, err := newHTTPClient(opts)
+	if err != nil {
+		return "", err
+	}
+	if opts.ReadTimeout > 0 {
+		client.Timeout = opts.ConnectTimeout + opts.ReadTimeout
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", downloadUrl, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// newHTTPClient 创建带连接超时的 HTTP 客户端，HttpProxy 不为空时通过代理访问
+func newHTTPClient(opts DownloadOptions) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: opts.ConnectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = opts.ConnectTimeout
+	transport.ResponseHeaderTimeout = opts.ReadTimeout
+	if opts.HttpProxy != "" {
+		proxyURL, err := url.Parse(opts.HttpProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// GithubURLs 返回 GitHub 下载地址的候选列表：配置了 githubProxy（且未使用 HTTP 代理）时先走代理，失败后直连
+func GithubURLs(console ui.UI, githubProxy, httpProxy, downloadUrl string) []string {
+	if httpProxy != "" || githubProxy == "" {
+		return []string{downloadUrl}
+	}
+	return []string{ProxyURL(console, githubProxy, downloadUrl), downloadUrl}
 }
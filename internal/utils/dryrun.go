@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bookandmusic/dev-tools/internal/ui"
+)
+
+type dryRunKey struct{}
+
+// PlannedAction 干跑模式下记录的一个动作
+type PlannedAction struct {
+	Kind   string // command / write / copy / download / extract / mkdir / remove / rename
+	Target string
+	Detail string // 文件差异、下载地址等附加信息
+}
+
+// DryRun 收集干跑模式下本应执行的动作，不触碰系统
+type DryRun struct {
+	mu      sync.Mutex
+	actions []PlannedAction
+	files   map[string]string // 计划写入的文件内容，后续读取以此为准
+}
+
+// WithDryRun 将 DryRun 放入 context，之后经过该 context 的写操作只会被记录
+func WithDryRun(ctx context.Context, d *DryRun) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, d)
+}
+
+// DryRunFromContext 返回 context 中的 DryRun，非干跑模式返回 nil
+func DryRunFromContext(ctx context.Context) *DryRun {
+	if ctx == nil {
+		return nil
+	}
+	d, _ := ctx.Value(dryRunKey{}).(*DryRun)
+	return d
+}
+
+// Record 记录一个计划动作
+func (d *DryRun) Record(kind, target, detail string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.actions = append(d.actions, PlannedAction{Kind: kind, Target: target, Detail: detail})
+}
+
+// RecordWrite 记录一次文件写入，并附上与当前文件内容（含之前计划的写入）的差异
+func (d *DryRun) RecordWrite(path, newContent string) {
+	oldContent := ""
+	if data, err := d.readFile(path); err == nil {
+		oldContent = string(data)
+	}
+	diff := UnifiedDiff(path, oldContent, newContent)
+	if diff == "" {
+		diff = "(no changes)"
+	}
+	d.Record("write", path, diff)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.files == nil {
+		d.files = make(map[string]string)
+	}
+	d.files[filepath.Clean(path)] = newContent
+}
+
+// readFile 优先返回计划写入的内容，否则读取磁盘
+func (d *DryRun) readFile(path string) ([]byte, error) {
+	d.mu.Lock()
+	content, ok := d.files[filepath.Clean(path)]
+	d.mu.Unlock()
+	if ok {
+		return []byte(content), nil
+	}
+	return os.ReadFile(filepath.Clean(path))
+}
+
+// Actions 返回已记录的动作
+func (d *DryRun) Actions() []PlannedAction {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]PlannedAction(nil), d.actions...)
+}
+
+// Print 输出计划动作列表
+func (d *DryRun) Print(console ui.UI) {
+	actions := d.Actions()
+	console.Info("Dry run: %d planned action(s), nothing was changed", len(actions))
+	for i, a := range actions {
+		console.Println("%3d. [%s] %s", i+1, a.Kind, a.Target)
+		for _, line := range strings.Split(strings.TrimRight(a.Detail, "\n"), "\n") {
+			if line != "" {
+				console.Println("       %s", line)
+			}
+		}
+	}
+}
+
+// ReadFile 读取文件，干跑模式下会看到之前计划写入的内容
+func ReadFile(ctx context.Context, path string) ([]byte, error) {
+	if d := DryRunFromContext(ctx); d != nil {
+		return d.readFile(path)
+	}
+	return os.ReadFile(filepath.Clean(path))
+}
+
+// WriteFile 写入文件并设置权限，干跑模式下只记录差异
+func WriteFile(ctx context.Context, path string, data []byte, perm os.FileMode) error {
+	if d := DryRunFromContext(ctx); d != nil {
+		d.RecordWrite(path, string(data))
+		return nil
+	}
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return err
+	}
+	return os.Chmod(path, perm)
+}
+
+// MkdirAll 创建目录，干跑模式下只记录
+func MkdirAll(ctx context.Context, path string, perm os.FileMode) error {
+	if d := DryRunFromContext(ctx); d != nil {
+		if !PathExists(path) {
+			d.Record("mkdir", path, "")
+		}
+		return nil
+	}
+	return os.MkdirAll(path, perm)
+}
+
+// RemoveAll 删除文件或目录，干跑模式下只记录
+func RemoveAll(ctx context.Context, path string) error {
+	if d := DryRunFromContext(ctx); d != nil {
+		if PathExists(path) {
+			d.Record("remove", path, "")
+		}
+		return nil
+	}
+	return os.RemoveAll(path)
+}
+
+// Rename 重命名文件或目录，干跑模式下只记录
+func Rename(ctx context.Context, oldPath, newPath string) error {
+	if d := DryRunFromContext(ctx); d != nil {
+		d.Record("rename", oldPath, "-> "+newPath)
+		return nil
+	}
+	return os.Rename(oldPath, newPath)
+}
+
+// TeeFile 通过 `[sudo] tee` 写入文件（用于需要 root 权限的路径），干跑模式下只记录差异
+func TeeFile(ctx context.Context, u ui.UI, env map[string]string, file, content string, sudo bool) error {
+	if d := DryRunFromContext(ctx); d != nil {
+		d.RecordWrite(file, content+"\n")
+		return nil
+	}
+	tee := "tee"
+	if sudo {
+		tee = "sudo tee"
+	}
+	cmd := fmt.Sprintf("cat <<'EOF' | %s %s > /dev/null\n%s\nEOF", tee, file, content)
+	return RunCommand(ctx, u, env, "bash", "-c", cmd)
+}
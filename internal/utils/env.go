@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -13,14 +14,11 @@ import (
 // UpdateEnvFile 更新 env 文件
 // mode = "add" 表示添加/更新变量
 // mode = "remove" 表示删除变量
-func UpdateEnvFile(envFile string, vars map[string]string, mode string) error {
+func UpdateEnvFile(ctx context.Context, envFile string, vars map[string]string, mode string) error {
 	// 读取已有 env 文件
-	envMap, err := godotenv.Read(envFile)
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to read env file: %w", err)
-	}
-	if envMap == nil {
-		envMap = make(map[string]string)
+	envMap, err := ReadEnvFile(ctx, envFile)
+	if err != nil {
+		return err
 	}
 
 	splitFlag := string(os.PathListSeparator)
@@ -37,22 +35,45 @@ func UpdateEnvFile(envFile string, vars map[string]string, mode string) error {
 	// 末尾追加 $PATH，避免重复
 	appendPathIfNeeded(envMap, splitFlag)
 
-	// 写回文件
-	if err := godotenv.Write(envMap, envFile); err != nil {
+	content, err := godotenv.Marshal(envMap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal env file: %w", err)
+	}
+
+	// 修正 godotenv 对 $PATH 的转义，并写回文件
+	fixed := strings.ReplaceAll(content, `\$PATH`, `$PATH`) + "\n"
+	if err := WriteFile(ctx, envFile, []byte(fixed), 0o600); err != nil {
 		return fmt.Errorf("failed to write env file: %w", err)
 	}
 
-	// 修正 godotenv 对 $PATH 的转义
-	content, err := os.ReadFile(envFile)
+	return nil
+}
+
+// ReadEnvFile 读取 env 文件，文件不存在时返回空 map
+func ReadEnvFile(ctx context.Context, envFile string) (map[string]string, error) {
+	content, err := ReadFile(ctx, envFile)
 	if err != nil {
-		return fmt.Errorf("failed to read env file for fix: %w", err)
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to read env file: %w", err)
 	}
-	fixed := strings.ReplaceAll(string(content), `\$PATH`, `$PATH`)
-	if err := os.WriteFile(envFile, []byte(fixed), 0o600); err != nil {
-		return fmt.Errorf("failed to write fixed env file: %w", err)
+	envMap, err := godotenv.Unmarshal(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse env file: %w", err)
 	}
+	return envMap, nil
+}
 
-	return nil
+// SplitPathList 拆分 PATH 类变量，忽略空项与 $PATH 占位符
+func SplitPathList(value string) []string {
+	var paths []string
+	for _, p := range strings.Split(value, string(os.PathListSeparator)) {
+		if p != "" && p != "$PATH" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
 }
 
 // handleAddMode 处理添加模式
@@ -108,7 +129,7 @@ func removePathVariable(envMap map[string]string, pathToRemove, splitFlag string
 	paths := strings.Split(existing, splitFlag)
 	newPaths := make([]string, 0, len(paths))
 	for _, p := range paths {
-		if p != pathToRemove && p != "$PATH" {
+		if p != pathToRemove && p != "$PATH" && p != "" {
 			newPaths = append(newPaths, p)
 		}
 	}
@@ -131,17 +152,9 @@ func appendPathIfNeeded(envMap map[string]string, splitFlag string) {
 	}
 }
 
-// MergeJSON 返回合并后的 JSON 字符串，不写文件
+// MergeJSON 将 updates 深度合并到 orig 后返回 JSON 字符串，不写文件
 func MergeJSON(orig map[string]interface{}, updates map[string]interface{}) (string, error) {
-	// 如果原始 map 为 nil，创建一个空 map
-	if orig == nil {
-		orig = make(map[string]interface{})
-	}
-
-	// 合并更新字段
-	for k, v := range updates {
-		orig[k] = v
-	}
+	orig = DeepMerge(orig, updates)
 
 	// 生成格式化 JSON 字符串
 	data, err := json.MarshalIndent(orig, "", "    ")
@@ -152,12 +165,30 @@ func MergeJSON(orig map[string]interface{}, updates map[string]interface{}) (str
 	return string(data), nil
 }
 
+// DeepMerge 将 src 递归合并到 dst 并返回 dst：两边都是对象的键继续合并，
+// 其余键以 src 为准，只在 dst 中出现的键原样保留
+func DeepMerge(dst, src map[string]interface{}) map[string]interface{} {
+	if dst == nil {
+		dst = make(map[string]interface{}, len(src))
+	}
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]interface{})
+		dstMap, dstIsMap := dst[k].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			dst[k] = DeepMerge(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+	return dst
+}
+
 // EnsureBlockInFile 确保文件中存在指定 block（startMark ~ endMark之间的内容会被替换）
-func EnsureBlockInFile(filePath, startMark, endMark, block string) error {
-	content, err := os.ReadFile(filePath)
+func EnsureBlockInFile(ctx context.Context, filePath, startMark, endMark, block string) error {
+	content, err := ReadFile(ctx, filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return os.WriteFile(filePath, []byte(block+"\n"), 0o600)
+			return WriteFile(ctx, filePath, []byte(block+"\n"), 0o600)
 		}
 		return fmt.Errorf("failed to read %s: %w", filePath, err)
 	}
@@ -169,19 +200,19 @@ func EnsureBlockInFile(filePath, startMark, endMark, block string) error {
 		re := regexp.MustCompile("(?s)" + regexp.QuoteMeta(startMark) + ".*?" + regexp.QuoteMeta(endMark))
 		newText := re.ReplaceAllString(text, block)
 		if newText != text {
-			return os.WriteFile(filePath, []byte(newText), 0o600)
+			return WriteFile(ctx, filePath, []byte(newText), 0o600)
 		}
 		return nil
 	}
 
 	// 否则，直接追加
 	newText := strings.TrimRight(text, "\n") + "\n" + block + "\n"
-	return os.WriteFile(filePath, []byte(newText), 0o600)
+	return WriteFile(ctx, filePath, []byte(newText), 0o600)
 }
 
 // RemoveLinesInFile 删除文件中 startMark ~ endMark 的内容
-func RemoveLinesInFile(filePath, startMark, endMark string) error {
-	content, err := os.ReadFile(filePath)
+func RemoveLinesInFile(ctx context.Context, filePath, startMark, endMark string) error {
+	content, err := ReadFile(ctx, filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil
@@ -197,5 +228,5 @@ func RemoveLinesInFile(filePath, startMark, endMark string) error {
 		return nil
 	}
 
-	return os.WriteFile(filePath, []byte(strings.TrimRight(newText, "\n")+"\n"), 0o600)
+	return WriteFile(ctx, filePath, []byte(strings.TrimRight(newText, "\n")+"\n"), 0o600)
 }
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -14,12 +16,17 @@ import (
 	"github.com/bookandmusic/dev-tools/internal/ui"
 )
 
-// ExtractTarGzWithProgress 解压 tar.gz 包，支持 strip 参数
-func ExtractTarGzWithProgress(console ui.UI, tarGzPath, targetDir string, strip int) error {
+// ExtractTarGzWithProgress 解压 tar.gz 包，支持 strip 参数，干跑模式下只记录
+func ExtractTarGzWithProgress(ctx context.Context, console ui.UI, tarGzPath, targetDir string, strip int) error {
 	// 清理路径
 	cleanTarGzPath := filepath.Clean(tarGzPath)
 	cleanTargetDir := filepath.Clean(targetDir)
 
+	if d := DryRunFromContext(ctx); d != nil {
+		d.Record("extract", cleanTarGzPath, fmt.Sprintf("-> %s (strip %d)", cleanTargetDir, strip))
+		return nil
+	}
+
 	// 打开文件
 	f, err := os.Open(cleanTarGzPath)
 	if err != nil {
@@ -124,8 +131,11 @@ func scanArchive(tr *tar.Reader, strip int) (int64, error) {
 	return totalSize, nil
 }
 
-// extractFiles 提取文件
+// extractFiles 提取文件，越出 targetDir 的条目会被拒绝
 func extractFiles(tr *tar.Reader, buf []byte, targetDir string, strip int, bar *progressbar.ProgressBar) error {
+	if err := os.MkdirAll(targetDir, 0o700); err != nil {
+		return err
+	}
 	for {
 		hdr, err := tr.Next()
 		if err == io.EOF {
@@ -135,14 +145,23 @@ func extractFiles(tr *tar.Reader, buf []byte, targetDir string, strip int, bar *
 			return err
 		}
 
+		// 绝对路径在 strip 时会丢掉开头的 /，需在此之前拒绝
+		if strings.HasPrefix(hdr.Name, "/") {
+			fmt.Println("")
+			return fmt.Errorf("illegal file path in archive: %s: absolute path", hdr.Name)
+		}
+
 		// strip 处理
 		relPath := stripPathComponents(hdr.Name, strip)
 		if relPath == "" {
 			continue
 		}
 
-		destPath := filepath.Join(targetDir, relPath)
-		cleanDestPath := filepath.Clean(destPath)
+		cleanDestPath, err := SafeJoin(targetDir, relPath)
+		if err != nil {
+			fmt.Println("")
+			return fmt.Errorf("illegal file path in archive: %s: %w", hdr.Name, err)
+		}
 
 		// 判断是否目录
 		isDir := hdr.Typeflag == tar.TypeDir || strings.HasSuffix(hdr.Name, "/")
@@ -154,6 +173,14 @@ func extractFiles(tr *tar.Reader, buf []byte, targetDir string, strip int, bar *
 			continue
 		}
 
+		// 符号链接与硬链接（如 python 发行包中的 bin/python3 -> python3.12）
+		if hdr.Typeflag == tar.TypeSymlink || hdr.Typeflag == tar.TypeLink {
+			if err := extractLink(hdr, targetDir, cleanDestPath, strip); err != nil {
+				return err
+			}
+			continue
+		}
+
 		// 普通文件
 		if err := extractRegularFile(tr, buf, cleanDestPath, bar); err != nil {
 			fmt.Println("")
@@ -212,6 +239,109 @@ func extractRegularFile(tr *tar.Reader, buf []byte, destPath string, bar *progre
 	return nil
 }
 
+// extractLink 创建归档中的符号链接或硬链接。
+// 符号链接不能是绝对路径，且相对链接所在目录解析后必须仍在 targetDir 内；硬链接的目标同样必须在 targetDir 内
+func extractLink(hdr *tar.Header, targetDir, destPath string, strip int) error {
+	if hdr.Typeflag == tar.TypeSymlink {
+		if err := checkSymlink(targetDir, destPath, hdr.Linkname); err != nil {
+			return fmt.Errorf("illegal symlink in archive: %s -> %s: %w", hdr.Name, hdr.Linkname, err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o700); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(destPath); err != nil {
+		return err
+	}
+
+	if hdr.Typeflag == tar.TypeSymlink {
+		return os.Symlink(hdr.Linkname, destPath)
+	}
+
+	linkTarget := stripPathComponents(hdr.Linkname, strip)
+	if linkTarget == "" {
+		return fmt.Errorf("invalid hard link target: %s", hdr.Linkname)
+	}
+	src, err := SafeJoin(targetDir, linkTarget)
+	if err != nil {
+		return fmt.Errorf("illegal hard link in archive: %s -> %s: %w", hdr.Name, hdr.Linkname, err)
+	}
+	return os.Link(src, destPath)
+}
+
+// checkSymlink 校验符号链接目标：拒绝绝对路径，以及从链接所在目录解析后越出 targetDir 的相对路径
+func checkSymlink(targetDir, destPath, linkname string) error {
+	if filepath.IsAbs(linkname) {
+		return fmt.Errorf("absolute link target")
+	}
+	realDir, err := resolveExisting(targetDir)
+	if err != nil {
+		return err
+	}
+	// destPath 的父目录已由 SafeJoin 解析为真实路径
+	resolved := filepath.Join(filepath.Dir(destPath), linkname)
+	if resolved != realDir && !WithinDir(realDir, resolved) {
+		return fmt.Errorf("link target is outside %s", targetDir)
+	}
+	return nil
+}
+
+// WithinDir 判断 path 清理后是否位于 dir 之内（不含 dir 本身）
+func WithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator)) && !filepath.IsAbs(rel)
+}
+
+// SafeJoin 将归档中的相对路径拼接到 dir 下，拒绝绝对路径、越出 dir 的路径，
+// 以及经由已存在的符号链接指向 dir 之外的路径。返回的路径中父目录已解析为真实路径；
+// "." 与 "./" 等指向 dir 本身的条目返回 dir 的真实路径
+func SafeJoin(dir, rel string) (string, error) {
+	if filepath.IsAbs(rel) {
+		return "", fmt.Errorf("absolute path %s", rel)
+	}
+	dest := filepath.Join(dir, rel)
+	if filepath.Clean(dest) == filepath.Clean(dir) {
+		return resolveExisting(dir)
+	}
+	if !WithinDir(dir, dest) {
+		return "", fmt.Errorf("%s is outside %s", rel, dir)
+	}
+	realDir, err := resolveExisting(dir)
+	if err != nil {
+		return "", err
+	}
+	parent, err := resolveExisting(filepath.Dir(dest))
+	if err != nil {
+		return "", err
+	}
+	if parent != realDir && !WithinDir(realDir, parent) {
+		return "", fmt.Errorf("%s is outside %s through a symlink", rel, dir)
+	}
+	return filepath.Join(parent, filepath.Base(dest)), nil
+}
+
+// resolveExisting 解析路径中已存在部分的符号链接，不存在的部分原样拼接
+func resolveExisting(path string) (string, error) {
+	path = filepath.Clean(path)
+	var rest []string
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
+
 // stripPathComponents 去掉路径前 n 层
 func stripPathComponents(path string, strip int) string {
 	parts := strings.Split(path, "/")
@@ -266,7 +396,7 @@ func RemoveFilesWithProgress(binDir string, names []string, console ui.UI) error
 }
 
 // CopyDirWithProgress 递归复制目录并显示文件数量进度条
-func CopyDirWithProgress(src, dst string, console ui.UI) error {
+func CopyDirWithProgress(ctx context.Context, src, dst string, console ui.UI) error {
 	// 先统计文件数量
 	var files []string
 	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
@@ -286,6 +416,11 @@ func CopyDirWithProgress(src, dst string, console ui.UI) error {
 		return nil
 	}
 
+	if d := DryRunFromContext(ctx); d != nil {
+		d.Record("copy", src, fmt.Sprintf("-> %s (%d files)", dst, len(files)))
+		return nil
+	}
+
 	bar := progressbar.NewOptions(
 		len(files),
 		progressbar.OptionSetDescription(fmt.Sprintf("Copying %s", filepath.Base(src))),
@@ -309,7 +444,7 @@ func CopyDirWithProgress(src, dst string, console ui.UI) error {
 		}
 
 		// 复制文件
-		if err := CopyFile(srcFile, dstFile); err != nil {
+		if err := CopyFile(ctx, srcFile, dstFile); err != nil {
 			return err
 		}
 
@@ -322,11 +457,15 @@ func CopyDirWithProgress(src, dst string, console ui.UI) error {
 	return nil
 }
 
-// copyFile 按块复制文件并保留权限
-func CopyFile(srcFile, dstFile string) error {
+// CopyFile 按块复制文件并保留权限，干跑模式下只记录
+func CopyFile(ctx context.Context, srcFile, dstFile string) error {
 	// 清理路径防止路径遍历攻击
 	cleanSrcFile := filepath.Clean(srcFile)
 	cleanDstFile := filepath.Clean(dstFile)
+	if d := DryRunFromContext(ctx); d != nil {
+		d.Record("copy", cleanSrcFile, "-> "+cleanDstFile)
+		return nil
+	}
 	src, err := os.Open(cleanSrcFile)
 	if err != nil {
 		return err
@@ -348,3 +487,63 @@ func CopyFile(srcFile, dstFile string) error {
 	_, err = io.CopyBuffer(dst, src, buf)
 	return err
 }
+
+// ExtractZip 解压 zip 包，支持 strip 参数，干跑模式下只记录
+func ExtractZip(ctx context.Context, zipPath, targetDir string, strip int) error {
+	cleanZipPath := filepath.Clean(zipPath)
+	cleanTargetDir := filepath.Clean(targetDir)
+
+	if d := DryRunFromContext(ctx); d != nil {
+		d.Record("extract", cleanZipPath, fmt.Sprintf("-> %s (strip %d)", cleanTargetDir, strip))
+		return nil
+	}
+
+	r, err := zip.OpenReader(cleanZipPath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		name := stripPathComponents(f.Name, strip)
+		if name == "" {
+			continue
+		}
+		// 防止 zip slip
+		destPath, err := SafeJoin(cleanTargetDir, name)
+		if err != nil {
+			return fmt.Errorf("illegal file path in archive: %s: %w", f.Name, err)
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(destPath, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := extractZipFile(f, destPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractZipFile 写出 zip 中的单个文件并保留权限
+func extractZipFile(f *zip.File, destPath string) error {
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+		return err
+	}
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	return err
+}
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+func TestSafeJoin(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "dest")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(root, filepath.Join(dir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		rel     string
+		want    string
+		wantErr bool
+	}{
+		{rel: ".", want: realDir},
+		{rel: "./", want: realDir},
+		{rel: "./a", want: filepath.Join(realDir, "a")},
+		{rel: "a/b/../c", want: filepath.Join(realDir, "a", "c")},
+		{rel: "../x", wantErr: true},
+		{rel: "a/../../x", wantErr: true},
+		{rel: "/etc/passwd", wantErr: true},
+		{rel: "escape/x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			got, err := SafeJoin(dir, tt.rel)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("SafeJoin(%q) = %s, want error", tt.rel, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SafeJoin(%q) error: %v", tt.rel, err)
+			}
+			if got != tt.want {
+				t.Errorf("SafeJoin(%q) = %s, want %s", tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+// tarEntry 描述测试归档中的一个条目
+type tarEntry struct {
+	name     string
+	typeflag byte
+	linkname string
+	body     string
+}
+
+func buildTar(t *testing.T, entries []tarEntry) *tar.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.linkname, Mode: 0o755, Size: int64(len(e.body))}
+		if e.typeflag != tar.TypeReg {
+			hdr.Size = 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if e.typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return tar.NewReader(&buf)
+}
+
+func TestExtractFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		strip   int
+		entries []tarEntry
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "dot entries",
+			entries: []tarEntry{
+				{name: "./", typeflag: tar.TypeDir},
+				{name: "./a", typeflag: tar.TypeReg, body: "a"},
+				{name: "./bin/", typeflag: tar.TypeDir},
+				{name: "./bin/b", typeflag: tar.TypeReg, body: "b"},
+			},
+			want: []string{"a", "bin/b"},
+		},
+		{
+			name:  "strip top directory",
+			strip: 1,
+			entries: []tarEntry{
+				{name: "pkg/", typeflag: tar.TypeDir},
+				{name: "pkg/a", typeflag: tar.TypeReg, body: "a"},
+			},
+			want: []string{"a"},
+		},
+		{
+			name: "relative symlink inside",
+			entries: []tarEntry{
+				{name: "a", typeflag: tar.TypeReg, body: "a"},
+				{name: "link", typeflag: tar.TypeSymlink, linkname: "a"},
+			},
+			want: []string{"a", "link"},
+		},
+		{
+			name:    "parent path",
+			entries: []tarEntry{{name: "../x", typeflag: tar.TypeReg, body: "x"}},
+			wantErr: true,
+		},
+		{
+			name:    "absolute path",
+			entries: []tarEntry{{name: "/tmp/x", typeflag: tar.TypeReg, body: "x"}},
+			wantErr: true,
+		},
+		{
+			name:    "absolute symlink",
+			entries: []tarEntry{{name: "link", typeflag: tar.TypeSymlink, linkname: "/etc"}},
+			wantErr: true,
+		},
+		{
+			name:    "escaping symlink",
+			entries: []tarEntry{{name: "sub/link", typeflag: tar.TypeSymlink, linkname: "../../x"}},
+			wantErr: true,
+		},
+		{
+			name: "write through symlink",
+			entries: []tarEntry{
+				{name: "link", typeflag: tar.TypeSymlink, linkname: "."},
+				{name: "link/../../x", typeflag: tar.TypeReg, body: "x"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "dest")
+			bar := progressbar.NewOptions64(-1, progressbar.OptionSetWriter(io.Discard))
+			err := extractFiles(buildTar(t, tt.entries), make([]byte, 1024), dir, tt.strip, bar)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("extractFiles succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractFiles error: %v", err)
+			}
+			for _, name := range tt.want {
+				if _, err := os.Lstat(filepath.Join(dir, name)); err != nil {
+					t.Errorf("%s not extracted: %v", name, err)
+				}
+			}
+		})
+	}
+}
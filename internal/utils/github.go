@@ -2,10 +2,7 @@ package utils
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,64 +10,6 @@ import (
 	"github.com/bookandmusic/dev-tools/internal/ui"
 )
 
-// GetLatestReleaseTag 查询 GitHub 仓库的最新 release tag
-// repoFullName 格式示例："docker/buildx"
-// proxy 为可选代理前缀，例如 "https://ghproxy.com/"
-// 如果不需要代理，传空字符串 "" 即可。
-func GetLatestReleaseTag(ui ui.UI, repoFullName, githubProxy, httpProxy string) (string, error) {
-	if repoFullName == "" || !strings.Contains(repoFullName, "/") {
-		return "", fmt.Errorf("invalid repoFullName: %s, expected format 'owner/repo'", repoFullName)
-	}
-
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repoFullName)
-	if httpProxy == "" {
-		apiURL = ProxyURL(ui, githubProxy, apiURL)
-	}
-
-	// 设置 HTTP 客户端
-	client := &http.Client{}
-	if httpProxy != "" {
-		proxyURL, err := url.Parse(httpProxy)
-		if err != nil {
-			ui.Error("Invalid HTTP proxy URL: %v", err)
-			return "", err
-		}
-		client.Transport = &http.Transport{
-			Proxy: http.ProxyURL(proxyURL),
-		}
-	}
-
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("User-Agent", "Go-http-client") // GitHub API 要求 UA
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("failed to get latest release: HTTP %d", resp.StatusCode)
-	}
-
-	var body struct {
-		TagName string `json:"tag_name"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
-		return "", err
-	}
-
-	if body.TagName == "" {
-		return "", fmt.Errorf("tag_name not found in release info")
-	}
-
-	return body.TagName, nil
-}
-
 // proxyURL 添加GitHub代理前缀
 func ProxyURL(ui ui.UI, githubProxy, url string) string {
 	if githubProxy != "" {
@@ -92,9 +31,9 @@ func IsGitRepo(ctx context.Context, ui ui.UI, path string) bool {
 		return false
 	}
 
-	// 验证git仓库状态
-	err := RunCommand(ctx, ui, map[string]string{}, "git", "-C", path, "rev-parse", "--is-inside-work-tree")
-	if err != nil {
+	// 验证git仓库状态，只读查询，dry-run 时同样执行
+	out, err := CommandOutput(ctx, "git", "-C", path, "rev-parse", "--is-inside-work-tree")
+	if err != nil || out != "true" {
 		ui.Info("路径 %s 不是有效的Git仓库: %v", path, err)
 		return false
 	}
@@ -121,13 +60,38 @@ func CloneRepoWithProxy(
 	if PathExists(path) {
 		ui.Warning("目录 %s 存在但不是git仓库，尝试备份", path)
 		backupDir := path + ".bak"
-		if err := os.Rename(path, backupDir); err != nil {
+		if err := Rename(ctx, path, backupDir); err != nil {
 			return fmt.Errorf("备份目录失败: %w", err)
 		}
 		ui.Info("已备份到: %s", backupDir)
 	}
 
+	if IsOffline(ctx) {
+		return fmt.Errorf("克隆 %s 失败: %w", repoURL, ErrOffline)
+	}
+
 	// 处理代理
+	url, env := gitProxy(ui, repoURL, httpProxy, githubProxy, env)
+	ui.Info("克隆仓库: %s -> %s", url, path)
+	return RunCommand(ctx, ui, env, "git", "clone", "--depth=1", url, path)
+}
+
+// CloneRepoRef 浅克隆仓库的指定分支或 tag 到 path，ref 为空时使用默认分支
+func CloneRepoRef(ctx context.Context, ui ui.UI, repoURL, ref, path, httpProxy, githubProxy string) error {
+	if IsOffline(ctx) {
+		return fmt.Errorf("克隆 %s 失败: %w", repoURL, ErrOffline)
+	}
+	url, env := gitProxy(ui, repoURL, httpProxy, githubProxy, nil)
+	args := []string{"clone", "--depth=1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	ui.Info("克隆仓库: %s -> %s", url, path)
+	return RunCommand(ctx, ui, env, "git", append(args, url, path)...)
+}
+
+// gitProxy 根据代理配置返回 git 使用的地址和环境变量
+func gitProxy(ui ui.UI, repoURL, httpProxy, githubProxy string, env map[string]string) (string, map[string]string) {
 	url := repoURL
 	if httpProxy != "" {
 		ui.Info("使用代理: %s", httpProxy)
@@ -139,9 +103,63 @@ func CloneRepoWithProxy(
 	} else if githubProxy != "" {
 		url = ProxyURL(ui, githubProxy, repoURL)
 	}
+	return url, env
+}
 
-	ui.Info("克隆仓库: %s -> %s", url, path)
-	return RunCommand(ctx, ui, env, "git", "clone", "--depth=1", url, path)
+// BundleRepo 完整克隆仓库并生成 git bundle（浅克隆无法生成 bundle），用于离线安装
+func BundleRepo(
+	ctx context.Context,
+	ui ui.UI,
+	repoURL string,
+	bundlePath string,
+	httpProxy string,
+	githubProxy string,
+	env map[string]string,
+) error {
+	if IsOffline(ctx) {
+		return fmt.Errorf("克隆 %s 失败: %w", repoURL, ErrOffline)
+	}
+	tmpDir, err := os.MkdirTemp("", "dtl-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	url, env := gitProxy(ui, repoURL, httpProxy, githubProxy, env)
+	repoDir := filepath.Join(tmpDir, "repo.git")
+	ui.Info("克隆仓库: %s", url)
+	if err := RunCommand(ctx, ui, env, "git", "clone", "--bare", url, repoDir); err != nil {
+		return err
+	}
+	return RunCommand(ctx, ui, env, "git", "-C", repoDir, "bundle", "create", bundlePath, "--all")
+}
+
+// CloneFromBundle 从 git bundle 克隆仓库，并将 origin 指回 originURL 以便之后在线更新
+func CloneFromBundle(ctx context.Context, ui ui.UI, bundleFile, path, originURL string, env map[string]string) error {
+	if IsGitRepo(ctx, ui, path) {
+		ui.Info("Git 仓库已存在: %s", path)
+		return nil
+	}
+	if PathExists(path) {
+		ui.Warning("目录 %s 存在但不是git仓库，尝试备份", path)
+		if err := Rename(ctx, path, path+".bak"); err != nil {
+			return fmt.Errorf("备份目录失败: %w", err)
+		}
+	}
+	ui.Info("从离线包克隆仓库: %s -> %s", bundleFile, path)
+	if err := RunCommand(ctx, ui, env, "git", "clone", bundleFile, path); err != nil {
+		return err
+	}
+	return RunCommand(ctx, ui, env, "git", "-C", path, "remote", "set-url", "origin", originURL)
+}
+
+// UpdateRepoFromBundle 使用 git bundle 快进更新仓库
+func UpdateRepoFromBundle(ctx context.Context, ui ui.UI, repoPath, bundleFile string, env map[string]string) error {
+	if !IsGitRepo(ctx, ui, repoPath) {
+		return fmt.Errorf("目录 %s 不是有效的 git 仓库", repoPath)
+	}
+	ui.Info("从离线包更新仓库: %s", repoPath)
+	return RunCommand(ctx, ui, env, "git", "-C", repoPath, "pull", "--ff-only", bundleFile, "HEAD")
 }
 
 // UpdateRepo 更新指定目录的 Git 仓库，可选 http/https 代理
@@ -151,6 +169,10 @@ func UpdateRepo(ctx context.Context, ui ui.UI, repoPath string, env map[string]s
 		return fmt.Errorf("目录 %s 不是有效的 git 仓库", repoPath)
 	}
 
+	if IsOffline(ctx) {
+		return fmt.Errorf("更新 %s 失败: %w", repoPath, ErrOffline)
+	}
+
 	// 设置代理
 	if httpProxy != "" {
 		ui.Info("使用代理: %s 更新仓库", httpProxy)
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ReadJSONFile 读取 JSON 记录文件到 v，文件不存在时保持 v 不变
+func ReadJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// WriteJSONFile 以缩进格式写入 JSON 记录文件，目录不存在时创建；干跑模式下只记录计划写入的内容
+func WriteJSONFile(ctx context.Context, path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := MkdirAll(ctx, filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return WriteFile(ctx, path, append(data, '\n'), 0o600)
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"context"
+	"errors"
+)
+
+type offlineKey struct{}
+
+// ErrOffline 离线模式下尝试访问网络时返回
+var ErrOffline = errors.New("network access is disabled in offline mode")
+
+// WithOffline 标记 context 为离线模式，之后的下载、git clone/pull 都会直接返回 ErrOffline
+func WithOffline(ctx context.Context) context.Context {
+	return context.WithValue(ctx, offlineKey{}, true)
+}
+
+// IsOffline 判断是否处于离线模式
+func IsOffline(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	offline, _ := ctx.Value(offlineKey{}).(bool)
+	return offline
+}
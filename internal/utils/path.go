@@ -59,7 +59,7 @@ func CreateIfNotExists(ctx context.Context, ui ui.UI, env map[string]string, pat
 	if sudo {
 		err = RunCommand(ctx, ui, env, "sudo", "mkdir", "-p", path)
 	} else {
-		err = os.MkdirAll(path, 0o700)
+		err = MkdirAll(ctx, path, 0o700)
 	}
 
 	if err != nil {
@@ -0,0 +1,180 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// bound 约束中的一个比较条件
+type bound struct {
+	op string // =、!=、>、>=、<、<=
+	v  *Version
+}
+
+// Constraint 版本约束，如 "~26.1"、"^2.29"、">=25 <27"、"1.22 || 1.23"。
+// 空格或逗号分隔的条件需同时满足，"||" 分隔的条件组满足其一即可；
+// 部分版本表示一个范围："26.1" 与 "26.1.x" 等价于 ">=26.1.0 <26.2.0"
+type Constraint struct {
+	raw        string
+	sets       [][]bound
+	prerelease bool // 约束中写了预发布版本
+}
+
+// ParseConstraint 解析版本约束
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{raw: s}
+	for _, group := range strings.Split(s, "||") {
+		var set []bound
+		for _, term := range strings.FieldsFunc(group, func(r rune) bool { return r == ' ' || r == ',' }) {
+			bounds, pre, err := parseTerm(term)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+			}
+			c.prerelease = c.prerelease || pre
+			set = append(set, bounds...)
+		}
+		if len(set) == 0 {
+			return nil, fmt.Errorf("invalid version constraint %q", s)
+		}
+		c.sets = append(c.sets, set)
+	}
+	return c, nil
+}
+
+// String 返回约束的原始写法
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// Check 判断版本是否满足约束
+func (c *Constraint) Check(v *Version) bool {
+	for _, set := range c.sets {
+		ok := true
+		for _, b := range set {
+			if !b.check(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// check 判断版本是否满足单个条件
+func (b bound) check(v *Version) bool {
+	c := Compare(v, b.v)
+	switch b.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
+
+// parseTerm 将单个条件展开为若干比较条件，pre 表示条件中写了预发布版本
+func parseTerm(term string) (bounds []bound, pre bool, err error) {
+	bounds, err = expandTerm(term)
+	return bounds, strings.Contains(strings.TrimLeft(term, "<>=!~^v"), "-"), err
+}
+
+// expandTerm 展开单个条件，部分版本与 ~、^ 转换为上下界
+func expandTerm(term string) ([]bound, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", "!=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(term, prefix) {
+			op, term = prefix, strings.TrimPrefix(term, prefix)
+			break
+		}
+	}
+	if term == "*" || term == "x" || term == "X" {
+		return []bound{{op: ">=", v: &Version{}}}, nil
+	}
+
+	// 去掉通配段，"26.x" 视为部分版本 "26"
+	fields := strings.Split(strings.TrimPrefix(term, "v"), ".")
+	for i, f := range fields {
+		if f == "x" || f == "X" || f == "*" {
+			fields = fields[:i]
+			break
+		}
+	}
+	v, err := Parse(strings.Join(fields, "."))
+	if err != nil {
+		return nil, err
+	}
+	lower := &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch, Pre: v.Pre}
+	partial := v.parts < 3
+
+	switch op {
+	case "", "=":
+		if !partial {
+			return []bound{{"=", lower}}, nil
+		}
+		return []bound{{">=", lower}, {"<", next(v, v.parts)}}, nil
+	case "!=":
+		if !partial {
+			return []bound{{"!=", lower}}, nil
+		}
+		return nil, fmt.Errorf("!= requires a full version: %s", term)
+	case ">":
+		if partial {
+			return []bound{{">=", next(v, v.parts)}}, nil
+		}
+		return []bound{{">", lower}}, nil
+	case ">=":
+		return []bound{{">=", lower}}, nil
+	case "<":
+		if partial && lower.Pre == "" {
+			// 与其他上界一致，"<27" 不包含 27.0.0 的预发布版本
+			lower.Pre = "0"
+		}
+		return []bound{{"<", lower}}, nil
+	case "<=":
+		if partial {
+			return []bound{{"<", next(v, v.parts)}}, nil
+		}
+		return []bound{{"<=", lower}}, nil
+	case "~":
+		// ~26 允许 26.x，~26.1 与 ~26.1.2 允许 26.1.x
+		return []bound{{">=", lower}, {"<", next(v, min(v.parts, 2))}}, nil
+	case "^":
+		// 不改变最左侧的非零段：^2.29 允许 2.x，^0.17.1 只允许 0.17.x
+		level := 1
+		switch {
+		case v.Major == 0 && v.Minor == 0 && v.parts == 3:
+			level = 3
+		case v.Major == 0 && v.parts >= 2:
+			level = 2
+		}
+		return []bound{{">=", lower}, {"<", next(v, level)}}, nil
+	}
+	return nil, fmt.Errorf("unknown operator in %s", term)
+}
+
+// next 返回第 level 段加一后的最小版本，如 next(26.1.3, 2) = 26.2.0-0
+func next(v *Version, level int) *Version {
+	n := &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch level {
+	case 1:
+		n.Major, n.Minor, n.Patch = v.Major+1, 0, 0
+	case 2:
+		n.Minor, n.Patch = v.Minor+1, 0
+	default:
+		n.Patch = v.Patch + 1
+	}
+	// 上界不包含下一版本的预发布版本
+	n.Pre = "0"
+	return n
+}
@@ -0,0 +1,109 @@
+package version
+
+import "testing"
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		// ~ 允许最后写出的 minor 内的 patch 更新
+		{"~26.1", "26.1.0", true},
+		{"~26.1", "26.1.15", true},
+		{"~26.1", "26.2.0", false},
+		{"~26.1", "26.0.9", false},
+		{"~26", "26.9.0", true},
+		{"~26", "27.0.0", false},
+		{"~26.1.2", "26.1.1", false},
+		{"~26.1.2", "26.1.3", true},
+
+		// 空格分隔的条件需同时满足
+		{">=25 <27", "25.0.0", true},
+		{">=25 <27", "26.1.4", true},
+		{">=25 <27", "27.0.0", false},
+		{">=25 <27", "24.0.9", false},
+		{">=25, <27", "26.0.0", true},
+
+		// ^ 不改变最左侧的非零段
+		{"^0.17.1", "0.17.1", true},
+		{"^0.17.1", "0.17.9", true},
+		{"^0.17.1", "0.18.0", false},
+		{"^0.17.1", "0.17.0", false},
+		{"^2.29", "2.99.0", true},
+		{"^2.29", "3.0.0", false},
+		{"^0.0.3", "0.0.4", false},
+
+		// 通配与部分版本
+		{"26.x", "26.0.0", true},
+		{"26.x", "26.9.9", true},
+		{"26.x", "27.0.0", false},
+		{"26.1.x", "26.1.7", true},
+		{"26.1", "26.2.0", false},
+		{"*", "0.0.1", true},
+
+		// 比较运算符
+		{">26.1", "26.1.9", false},
+		{">26.1", "26.2.0", true},
+		{"<=26.1", "26.1.9", true},
+		{"<=26.1", "26.2.0", false},
+		{"!=26.1.0", "26.1.0", false},
+		{"=26.1.0", "26.1.0", true},
+
+		// || 分隔的条件组满足其一即可
+		{"1.22 || 1.23", "1.23.4", true},
+		{"1.22 || 1.23", "1.24.0", false},
+
+		// 上界不包含下一版本的预发布版本
+		{"<27", "27.0.0-rc.1", false},
+		{"~26.1", "26.2.0-beta.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint+"_"+tt.version, func(t *testing.T) {
+			c, err := ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("ParseConstraint(%q) error: %v", tt.constraint, err)
+			}
+			v, err := Parse(tt.version)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.version, err)
+			}
+			if got := c.Check(v); got != tt.want {
+				t.Errorf("%q.Check(%s) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	for _, s := range []string{"", ">=", "~abc", "!=26.1", "26.1 ||", "@1.0"} {
+		t.Run(s, func(t *testing.T) {
+			if c, err := ParseConstraint(s); err == nil {
+				t.Errorf("ParseConstraint(%q) = %v, want error", s, c)
+			}
+		})
+	}
+}
+
+func TestConstraintPrerelease(t *testing.T) {
+	tests := []struct {
+		constraint string
+		want       bool
+	}{
+		{">=25 <27", false},
+		{"~26.1", false},
+		{">=27.0.0-rc.1", true},
+		{"v28.0.0-beta.1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			c, err := ParseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("ParseConstraint(%q) error: %v", tt.constraint, err)
+			}
+			if c.prerelease != tt.want {
+				t.Errorf("%q prerelease = %v, want %v", tt.constraint, c.prerelease, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version 语义化版本号。解析时接受 v 前缀、省略的 minor/patch（视为 0），
+// 以及 Go 风格的预发布后缀（如 "1.23rc1" 视为 "1.23.0-rc1"）
+type Version struct {
+	Major, Minor, Patch int
+	Pre                 string // 预发布标识，如 "rc.1"
+	Build               string // 构建元数据，如 "k3s1"，不参与比较
+	Original            string // 解析前的原始写法
+	parts               int    // 实际写出的数字段数，用于约束中的部分版本
+}
+
+// Parse 解析版本号
+func Parse(s string) (*Version, error) {
+	v := &Version{Original: s}
+	rest := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	rest, v.Build, _ = strings.Cut(rest, "+")
+
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i := 0; i < len(nums); i++ {
+		end := 0
+		for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("invalid version: %q", s)
+		}
+		n, err := strconv.Atoi(rest[:end])
+		if err != nil {
+			return nil, fmt.Errorf("invalid version: %q", s)
+		}
+		*nums[i] = n
+		v.parts++
+		rest = rest[end:]
+		if !strings.HasPrefix(rest, ".") || i == len(nums)-1 {
+			break
+		}
+		rest = rest[1:]
+	}
+
+	switch {
+	case rest == "":
+	case rest[0] == '-':
+		v.Pre = rest[1:]
+	case isLetter(rest[0]):
+		v.Pre = rest
+	default:
+		return nil, fmt.Errorf("invalid version: %q", s)
+	}
+	if v.Pre == "" && strings.HasPrefix(rest, "-") {
+		return nil, fmt.Errorf("invalid version: %q", s)
+	}
+	return v, nil
+}
+
+// isLetter 判断字符是否为 ASCII 字母
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// String 返回不带 v 前缀的规范写法
+func (v *Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Prerelease 是否为预发布版本
+func (v *Version) Prerelease() bool {
+	return v.Pre != ""
+}
+
+// Compare 按语义化版本规则比较，返回 -1、0、1；构建元数据不参与比较
+func Compare(a, b *Version) int {
+	for _, d := range [][2]int{{a.Major, b.Major}, {a.Minor, b.Minor}, {a.Patch, b.Patch}} {
+		if d[0] != d[1] {
+			if d[0] < d[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return comparePre(a.Pre, b.Pre)
+}
+
+// comparePre 比较预发布标识：没有预发布标识的版本更大，数字段按数值比较
+func comparePre(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(as) - len(bs))
+}
+
+// sign 返回 n 的符号
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// compareBuild 比较构建元数据，只用于在相同版本间挑选：
+// 去掉末尾数字后相同时按数值比较，"k3s10" 大于 "k3s2"，否则按字符串比较
+func compareBuild(a, b string) int {
+	ap, an := splitNumber(a)
+	bp, bn := splitNumber(b)
+	if ap == bp && an >= 0 && bn >= 0 {
+		return sign(an - bn)
+	}
+	return strings.Compare(a, b)
+}
+
+// splitNumber 拆分末尾的数字，"k3s10" 返回 "k3s" 与 10，没有数字时返回 -1
+func splitNumber(s string) (string, int) {
+	i := len(s)
+	for i > 0 && s[i-1] >= '0' && s[i-1] <= '9' {
+		i--
+	}
+	n, err := strconv.Atoi(s[i:])
+	if err != nil {
+		return s, -1
+	}
+	return s[:i], n
+}
+
+// IsLatest 版本未固定：空字符串、latest 或 latest-stable，均表示最新的正式版本
+func IsLatest(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "latest", "latest-stable":
+		return true
+	}
+	return false
+}
+
+// IsExact 是否为完整的 x.y.z 版本，无需查询发布列表即可使用
+func IsExact(s string) bool {
+	v, err := Parse(s)
+	return err == nil && v.parts == 3
+}
+
+// IsConstraint 是否为需要在发布列表中查找的约束，如 "26.1"、"~26.1"、">=25 <27"
+func IsConstraint(s string) bool {
+	if IsLatest(s) || IsExact(s) {
+		return false
+	}
+	_, err := ParseConstraint(s)
+	return err == nil
+}
+
+// Same 比较两个版本是否相同，忽略 v 前缀；无法解析时按字符串比较
+func Same(a, b string) bool {
+	va, errA := Parse(a)
+	vb, errB := Parse(b)
+	if errA != nil || errB != nil {
+		return strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
+	}
+	return Compare(va, vb) == 0 && va.Build == vb.Build
+}
+
+// Match 判断已安装的版本是否满足配置：latest 总是满足，完整版本要求相同，其余按约束判断
+func Match(constraint, installed string) bool {
+	switch {
+	case IsLatest(constraint):
+		return true
+	case IsExact(constraint):
+		return Same(constraint, installed)
+	}
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	v, err := Parse(installed)
+	return err == nil && c.Check(v)
+}
+
+// Resolve 在候选版本中找出满足约束的最高版本，返回候选中的原始写法。
+// 预发布版本只有在 prerelease 为 true 或约束本身写了预发布版本时才会被选中，无法解析的候选会被忽略
+func Resolve(constraint string, candidates []string, prerelease bool) (string, error) {
+	var c *Constraint
+	if !IsLatest(constraint) {
+		var err error
+		if c, err = ParseConstraint(constraint); err != nil {
+			return "", err
+		}
+		prerelease = prerelease || c.prerelease
+	}
+
+	var best *Version
+	for _, candidate := range candidates {
+		v, err := Parse(candidate)
+		if err != nil || (v.Prerelease() && !prerelease) || (c != nil && !c.Check(v)) {
+			continue
+		}
+		if best == nil || Compare(v, best) > 0 || (Compare(v, best) == 0 && compareBuild(v.Build, best.Build) > 0) {
+			best = v
+		}
+	}
+	if best == nil {
+		return "", fmt.Errorf("no version matches %q", constraint)
+	}
+	return best.Original, nil
+}
@@ -0,0 +1,161 @@
+package version
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "26.1.3", want: "26.1.3"},
+		{in: "v1.30.5+k3s1", want: "1.30.5+k3s1"},
+		{in: "1.22", want: "1.22.0"},
+		{in: "1.23rc1", want: "1.23.0-rc1"},
+		{in: "2.0.0-beta.2", want: "2.0.0-beta.2"},
+		{in: "", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "1.2.3-", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v, err := Parse(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %v, want error", tt.in, v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.in, err)
+			}
+			if got := v.String(); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"26.1.3", "26.1.3", 0},
+		{"26.1.3", "26.1.10", -1},
+		{"27.0.0", "26.9.9", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.30.5+k3s1", "1.30.5+k3s10", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			a, _ := Parse(tt.a)
+			b, _ := Parse(tt.b)
+			if got := Compare(a, b); got != tt.want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		in                        string
+		latest, exact, constraint bool
+	}{
+		{in: "", latest: true},
+		{in: "latest", latest: true},
+		{in: "latest-stable", latest: true},
+		{in: "26.1.3", exact: true},
+		{in: "v1.30.5+k3s1", exact: true},
+		{in: "26.1", constraint: true},
+		{in: "~26.1", constraint: true},
+		{in: ">=25 <27", constraint: true},
+		{in: "26.x", constraint: true},
+		{in: "not-a-version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := IsLatest(tt.in); got != tt.latest {
+				t.Errorf("IsLatest(%q) = %v, want %v", tt.in, got, tt.latest)
+			}
+			if got := IsExact(tt.in); got != tt.exact {
+				t.Errorf("IsExact(%q) = %v, want %v", tt.in, got, tt.exact)
+			}
+			if got := IsConstraint(tt.in); got != tt.constraint {
+				t.Errorf("IsConstraint(%q) = %v, want %v", tt.in, got, tt.constraint)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		constraint, installed string
+		want                  bool
+	}{
+		{"latest", "1.0.0", true},
+		{"26.1.3", "v26.1.3", true},
+		{"26.1.3", "26.1.4", false},
+		{"v1.30.5+k3s1", "1.30.5+k3s1", true},
+		{"v1.30.5+k3s1", "1.30.5+k3s2", false},
+		{"~26.1", "26.1.9", true},
+		{"~26.1", "26.2.0", false},
+		{"26.x", "26.3.1", true},
+		{"26.x", "27.0.0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint+"_"+tt.installed, func(t *testing.T) {
+			if got := Match(tt.constraint, tt.installed); got != tt.want {
+				t.Errorf("Match(%q, %q) = %v, want %v", tt.constraint, tt.installed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	releases := []string{"v25.0.5", "v26.0.2", "v26.1.0", "v26.1.4", "v27.0.0-rc.1", "v27.0.1", "v28.0.0-beta.1"}
+	tests := []struct {
+		name       string
+		constraint string
+		candidates []string
+		prerelease bool
+		want       string
+		wantErr    bool
+	}{
+		{name: "latest", constraint: "latest", candidates: releases, want: "v27.0.1"},
+		{name: "latest-stable", constraint: "latest-stable", candidates: releases, want: "v27.0.1"},
+		{name: "latest with prerelease", constraint: "latest", candidates: releases, prerelease: true, want: "v28.0.0-beta.1"},
+		{name: "tilde", constraint: "~26.1", candidates: releases, want: "v26.1.4"},
+		{name: "range", constraint: ">=25 <27", candidates: releases, want: "v26.1.4"},
+		{name: "wildcard", constraint: "26.x", candidates: releases, want: "v26.1.4"},
+		{name: "partial", constraint: "26.0", candidates: releases, want: "v26.0.2"},
+		{name: "caret zero major", constraint: "^0.17.1", candidates: []string{"v0.17.0", "v0.17.1", "v0.17.3", "v0.18.0"}, want: "v0.17.3"},
+		{name: "prerelease excluded", constraint: ">=27", candidates: releases, want: "v27.0.1"},
+		{name: "prerelease in constraint", constraint: ">=28.0.0-0", candidates: releases, want: "v28.0.0-beta.1"},
+		{name: "build suffix compared numerically", constraint: "1.30.5", candidates: []string{"v1.30.5+k3s1", "v1.30.5+k3s10", "v1.30.5+k3s2"}, want: "v1.30.5+k3s10"},
+		{name: "build suffix in latest", constraint: "latest", candidates: []string{"v1.30.5+k3s10", "v1.30.5+k3s9"}, want: "v1.30.5+k3s10"},
+		{name: "unparsable candidates ignored", constraint: "latest", candidates: []string{"nightly", "v1.2.3"}, want: "v1.2.3"},
+		{name: "no match", constraint: "~24.0", candidates: releases, wantErr: true},
+		{name: "invalid constraint", constraint: ">=abc", candidates: releases, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.constraint, tt.candidates, tt.prerelease)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Resolve(%q) = %s, want error", tt.constraint, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q) error: %v", tt.constraint, err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %s, want %s", tt.constraint, got, tt.want)
+			}
+		})
+	}
+}
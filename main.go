@@ -4,8 +4,11 @@ import (
 	"os"
 
 	"github.com/bookandmusic/dev-tools/cmd"
+	_ "github.com/bookandmusic/dev-tools/internal/manager/soft/containerd"
 	_ "github.com/bookandmusic/dev-tools/internal/manager/soft/docker"
+	_ "github.com/bookandmusic/dev-tools/internal/manager/soft/k3s"
 	_ "github.com/bookandmusic/dev-tools/internal/manager/soft/ohmyzsh"
+	_ "github.com/bookandmusic/dev-tools/internal/manager/soft/podman"
 	_ "github.com/bookandmusic/dev-tools/internal/manager/soft/self"
 )
 